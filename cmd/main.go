@@ -2,16 +2,94 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 	"warships/pkg/api"
 	"warships/pkg/game"
+	"warships/pkg/layout"
+	"warships/pkg/rules"
+	"warships/pkg/strategy"
 )
 
 func main() {
+	server := flag.String("server", api.DefaultBaseURL, "base URL of the warships server API")
+	timeout := flag.Duration("timeout", api.DefaultTimeout, "timeout of a single request to the server")
+	variant := flag.String("variant", "classic", "board and fleet to play, one of "+strings.Join(rules.Variants(), ", "))
+	seed := flag.Int64("seed", 0, "seed of the random layout generator, 0 picks a new one every run")
+	layoutFile := flag.String("layout", "", "file with the fleet layout to play every game with, as a grid or a list like A1-A4 C3")
+	hints := flag.Int("hints", 3, "number of fields recommended by the hint overlay, toggled with h during the game")
+	autopilot := flag.Bool("autopilot", false, "fire automatically on every turn, toggled with p during the game")
+	think := flag.Duration("think", 500*time.Millisecond, "time the autopilot waits before every shot")
+	strategyName := flag.String("strategy", "density", "strategy of the autopilot, one of "+strings.Join(strategy.Names(), ", "))
+	noise := flag.Float64("noise", 0, "chance from 0 to 1 that the autopilot fires at a random field instead")
+	hintStrategy := flag.String("hint-strategy", "density", "strategy recommending the hinted fields")
+	recordGames := flag.Bool("record", true, "record every game to a JSON lines file")
+	recordDir := flag.String("record-dir", "", "directory games are recorded to, defaults to games in the config directory")
+	replayFile := flag.String("replay", "", "replay the game recorded in this file and exit")
+	flag.Parse()
+
+	cfg, err := rules.Variant(*variant)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
 	c := make(chan api.GameStatus)
 	s := make(chan string)
 	state := make(chan api.GameState)
 
 	ctx := context.Background()
-	app := game.NewApp(c, s, state)
+	app := game.NewApp(c, s, state,
+		api.WithBaseURL(*server),
+		api.WithTimeout(*timeout),
+	)
+	if err := app.SetConfig(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	app.SetHintCount(*hints)
+	autoStrategy, err := strategy.New(*strategyName, strategy.Options{Noise: *noise})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	app.SetStrategy(autoStrategy)
+	hinter, err := strategy.New(*hintStrategy, strategy.Options{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	app.SetHintStrategy(hinter)
+	app.SetThinkTime(*think)
+	app.SetAutopilot(*autopilot)
+	if *recordDir != "" {
+		app.SetRecordDir(*recordDir)
+	}
+	if !*recordGames {
+		app.SetRecordDir("")
+	}
+	if *seed != 0 {
+		app.SetLayoutSeed(*seed)
+	}
+	if *layoutFile != "" {
+		l, err := layout.Load(*layoutFile, cfg)
+		if err == nil {
+			err = app.SetLayout(l)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	if *replayFile != "" {
+		if err := app.Replay(ctx, *replayFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	app.Menu(ctx)
 }
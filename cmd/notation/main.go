@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"warships/pkg/notation"
+	"warships/pkg/record"
+)
+
+func main() {
+	to := flag.String("to", "notation", "output format: notation, code or jsonl")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-to notation|code|jsonl] game...\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "A game is a JSONL recording, a file in the notation, a file holding a game code or a game code.")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *to == "jsonl" && flag.NArg() > 1 {
+		fail(fmt.Errorf("a recording holds a single game, got %d", flag.NArg()))
+	}
+
+	for i, arg := range flag.Args() {
+		g, err := notation.Load(arg)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", arg, err))
+		}
+		var out string
+		switch *to {
+		case "notation":
+			out, err = notation.Encode(g)
+			if i > 0 {
+				out = "\n" + out
+			}
+		case "code":
+			out, err = notation.EncodeCode(g)
+			out += "\n"
+		case "jsonl":
+			err = record.Write(os.Stdout, g)
+		default:
+			err = fmt.Errorf("unknown output format %q", *to)
+		}
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", arg, err))
+		}
+		fmt.Print(out)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
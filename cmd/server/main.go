@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+	"warships/pkg/rules"
+	"warships/pkg/server"
+	"warships/pkg/strategy"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	turnTimeout := flag.Duration("turn-timeout", 60*time.Second, "time a player has to fire")
+	lobbyTimeout := flag.Duration("lobby-timeout", 60*time.Second, "how long a waiting session lives without a refresh")
+	endedTimeout := flag.Duration("ended-timeout", 5*time.Minute, "how long an ended game and its sessions are kept")
+	verbose := flag.Bool("v", false, "log every request")
+	variant := flag.String("variant", "classic", "board and fleet to play, one of "+strings.Join(rules.Variants(), ", "))
+	bot := flag.String("bot", "random", "strategy of the wpbot opponent, one of "+strings.Join(strategy.Names(), ", "))
+	botNoise := flag.Float64("bot-noise", 0, "chance from 0 to 1 that the wpbot fires at a random field instead")
+	flag.Parse()
+
+	cfg, err := rules.Variant(*variant)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// fail on a bad strategy now rather than in the first bot game
+	if _, err := strategy.New(*bot, strategy.Options{Noise: *botNoise}); err != nil {
+		log.Fatal(err)
+	}
+
+	srv := server.New(server.Config{
+		TurnTimeout:  *turnTimeout,
+		LobbyTimeout: *lobbyTimeout,
+		EndedTimeout: *endedTimeout,
+		Rules:        cfg,
+		Opponent: func() server.Opponent {
+			seed := time.Now().UnixNano()
+			s, _ := strategy.New(*bot, strategy.Options{Noise: *botNoise, Seed: seed})
+			return server.StrategyOpponent(s, seed)
+		},
+	})
+
+	var handler http.Handler = srv
+	if *verbose {
+		handler = logRequests(srv)
+	}
+	httpServer := &http.Server{Addr: *addr, Handler: handler}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// requests apply the timers on their own, the ticker covers idle games
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				srv.Tick()
+			}
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("warships server listening on %s, API at %s, playing %v against the %s bot", *addr, server.Prefix, cfg, *bot)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		log.Printf("%s %s %s", r.Method, r.URL.Path, time.Since(start))
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"time"
+	"warships/pkg/rules"
+	"warships/pkg/sim"
+	"warships/pkg/strategy"
+)
+
+func main() {
+	games := flag.Int("games", 1000, "games played in every matchup")
+	seed := flag.Int64("seed", 1, "seed of the whole simulation")
+	variant := flag.String("variant", "classic", "board and fleet to play, one of "+strings.Join(rules.Variants(), ", "))
+	players := flag.String("players", strings.Join(strategy.Names(), ","),
+		"comma separated players as strategy[:noise][/generator], generators are "+strings.Join(sim.Generators(), ", "))
+	workers := flag.Int("workers", runtime.NumCPU(), "games played at once")
+	asJSON := flag.Bool("json", false, "print the results as JSON instead of a table")
+	flag.Parse()
+
+	cfg, err := rules.Variant(*variant)
+	if err != nil {
+		fail(err)
+	}
+	var ps []sim.Player
+	for _, s := range strings.Split(*players, ",") {
+		p, err := sim.ParsePlayer(s)
+		if err != nil {
+			fail(err)
+		}
+		ps = append(ps, p)
+	}
+	// every player meets every other one, a single player plays itself
+	var matchups []sim.Matchup
+	for i := range ps {
+		for j := i + 1; j < len(ps); j++ {
+			matchups = append(matchups, sim.Matchup{A: ps[i], B: ps[j]})
+		}
+	}
+	if len(ps) == 1 {
+		matchups = append(matchups, sim.Matchup{A: ps[0], B: ps[0]})
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	start := time.Now()
+	results, err := sim.Run(ctx, sim.Config{Rules: cfg, Games: *games, Seed: *seed, Workers: *workers}, matchups)
+	if err != nil {
+		fail(err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fail(err)
+		}
+		return
+	}
+	fmt.Printf("%d game(s) of %v per matchup in %s\n\n", *games, cfg, time.Since(start).Round(time.Millisecond))
+	sim.WriteTable(os.Stdout, results)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,168 @@
+// Package apitest provides an in-process fake of the warships server for tests
+// and offline development. It runs the pkg/server game engine behind httptest,
+// with a controllable clock and on-demand error injection so that every error
+// path of api.Client can be exercised.
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/server"
+)
+
+// Prefix is the path under which the API is served
+const Prefix = server.Prefix
+
+type (
+	// Config configures the game engine, Clock is overridden by the fake
+	Config = server.Config
+	// Opponent plays the wpbot side of a bot game
+	Opponent = server.Opponent
+	// ScriptedOpponent is an opponent with a fixed layout and a fixed list of shots
+	ScriptedOpponent = server.ScriptedOpponent
+	// Shot is a shot already fired by the opponent together with its result
+	Shot = server.Shot
+)
+
+// RandomOpponent returns an opponent with a random layout that fires at random untouched cells
+var RandomOpponent = server.RandomOpponent
+
+// Fault is an error response injected in place of the real one
+type Fault struct {
+	// Path is the endpoint to fail, e.g. api.FireURL; empty matches every endpoint
+	Path string
+	// Method restricts the fault to one HTTP method; empty matches every method
+	Method string
+	// Status is the HTTP status code to respond with
+	Status int
+	// Message is sent in the error body
+	Message string
+	// RetryAfter is sent in the Retry-After header when positive
+	RetryAfter time.Duration
+	// Times is the number of requests to fail, values below 1 mean once
+	Times int
+}
+
+// Server is a fake warships server
+type Server struct {
+	*httptest.Server
+	engine *server.Server
+
+	mu       sync.Mutex
+	offset   time.Duration
+	faults   []*Fault
+	requests map[string]int
+}
+
+// NewServer starts a fake server, call Close when done
+func NewServer(cfg Config) *Server {
+	s := &Server{requests: map[string]int{}}
+	cfg.Clock = s.now
+	s.engine = server.New(cfg)
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// BaseURL returns the URL to pass to api.WithBaseURL
+func (s *Server) BaseURL() string {
+	return s.URL + Prefix
+}
+
+// Client returns an api.Client talking to the fake server, opts are applied last
+func (s *Server) Client(opts ...api.Option) *api.Client {
+	return api.NewClient(append([]api.Option{api.WithBaseURL(s.BaseURL())}, opts...)...)
+}
+
+// Inject makes the server answer matching requests with the given fault
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Times < 1 {
+		f.Times = 1
+	}
+	if f.Message == "" {
+		f.Message = http.StatusText(f.Status)
+	}
+	s.faults = append(s.faults, &f)
+}
+
+// FailNext makes the next request to path fail with status
+func (s *Server) FailNext(path string, status int) {
+	s.Inject(Fault{Path: path, Status: status})
+}
+
+// ClearFaults drops every pending fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the number of requests received for path, faults included
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// Advance moves the server clock forward, firing turn and lobby timers
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	s.offset += d
+	s.mu.Unlock()
+	s.engine.Tick()
+}
+
+// Expire drops the session of token, as if it had timed out, a game in
+// progress is won by the opponent
+func (s *Server) Expire(token string) {
+	s.engine.Expire(token)
+}
+
+func (s *Server) now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Now().Add(s.offset)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, Prefix)
+
+	s.mu.Lock()
+	s.requests[path]++
+	f := s.takeFault(r.Method, path)
+	s.mu.Unlock()
+
+	if f == nil {
+		s.engine.ServeHTTP(w, r)
+		return
+	}
+	if f.RetryAfter > 0 {
+		secs := int((f.RetryAfter + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(f.Status)
+	json.NewEncoder(w).Encode(api.ErrorMessage{Message: f.Message})
+}
+
+// takeFault consumes the first fault matching the request, if any
+func (s *Server) takeFault(method, path string) *Fault {
+	for i, f := range s.faults {
+		if (f.Path != "" && f.Path != path) || (f.Method != "" && f.Method != method) {
+			continue
+		}
+		f.Times--
+		if f.Times == 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+		return f
+	}
+	return nil
+}
@@ -2,10 +2,10 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -18,510 +18,287 @@ const (
 	AbandonGameURL = "/game/abandon"
 	GameDescURL    = "/game/desc"
 	RefreshURL     = "/game/refresh"
+	ListURL        = "/list"
+	LobbyURL       = "/lobby"
+	StatsURL       = "/stats"
 )
 
 type Client struct {
-	BaseURL string
-	Token   string
-	Client  *http.Client
+	BaseURL   string
+	Token     string
+	UserAgent string
+	Client    *http.Client
+	Retry     RetryPolicy
 }
 
-func NewClient(baseURL string, token string) *Client {
-	return &Client{
-		BaseURL: baseURL,
-		Token:   token,
+// NewClient returns a Client talking to DefaultBaseURL, customised by opts
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		BaseURL:   DefaultBaseURL,
+		UserAgent: DefaultUserAgent,
 		Client: &http.Client{
-			Timeout: time.Second * 10,
+			Timeout: DefaultTimeout,
 		},
+		Retry: DefaultRetryPolicy(),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-func (c *Client) GetGameStatus() (GameStatus, error) {
-	req, err := http.NewRequest(http.MethodGet, c.BaseURL+GameURL, nil)
-	if err != nil {
-		return GameStatus{}, err
+// newRequest builds a request for the given endpoint, encoding data as the JSON body if it is not nil
+func (c *Client) newRequest(ctx context.Context, method, endpoint string, data interface{}) (*http.Request, error) {
+	var body io.Reader
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
 	}
-	req.Header.Set("X-Auth-Token", c.Token)
 
-	resp, err := c.Client.Do(req)
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+endpoint, body)
 	if err != nil {
-		return GameStatus{}, err
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return GameStatus{}, err
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
 	}
-
-	switch resp.StatusCode {
-	case 200:
-		var gameState GameStatus
-		if err := json.Unmarshal(body, &gameState); err != nil {
-			return GameStatus{}, err
-		}
-		return gameState, nil
-	case 401:
-		var errResp UnauthorizedError
-		json.Unmarshal(body, &errResp)
-		return GameStatus{}, err
-	case 403:
-		var errResp ForbiddenError
-		json.Unmarshal(body, &errResp)
-		return GameStatus{}, err
-	case 429:
-		var errResp RateLimitExceededError
-		json.Unmarshal(body, &errResp)
-		return GameStatus{}, err
-	default:
-		all, _ := ioutil.ReadAll(resp.Body)
-		return GameStatus{}, errors.New(fmt.Sprintf("unexpected API error %v %v", resp.StatusCode, fmt.Sprintf("%s", all)))
+	if c.Token != "" {
+		req.Header.Set("X-Auth-Token", c.Token)
 	}
-}
-func (c *Client) StartGame(nick, desc, targetNick string, coords []string, botGame bool) (string, error) {
-	bodyData := map[string]interface{}{
-		"coords":      coords,
-		"desc":        desc,
-		"nick":        nick,
-		"target_nick": targetNick,
-		"wpbot":       botGame,
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
+	return req, nil
+}
 
-	body, err := json.Marshal(bodyData)
-	if err != nil {
-		return "", err
-	}
+// do sends req and decodes the response into out, see decodeResponse.
+// Failed attempts are retried according to c.Retry; GET requests are
+// treated as idempotent, everything else is not.
+func (c *Client) do(req *http.Request, endpoint string, out interface{}) (*http.Response, error) {
+	idempotent := req.Method == http.MethodGet
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(req, endpoint, out)
+		if err == nil || attempt >= c.Retry.MaxAttempts || !c.Retry.shouldRetry(err, idempotent) {
+			return resp, err
+		}
 
-	req, err := http.NewRequest(http.MethodPost, c.BaseURL+GameURL, bytes.NewBuffer(body))
-	if err != nil {
-		return "", err
+		wait := c.Retry.backoff(attempt, err)
+		if c.Retry.MaxElapsed > 0 && time.Since(start)+wait > c.Retry.MaxElapsed {
+			return resp, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+
+		if req.Body != nil && req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
 	}
-	req.Header.Set("X-Auth-Token", c.Token)
-	req.Header.Set("Content-Type", "application/json")
+}
 
+// send performs a single attempt of req
+func (c *Client) send(req *http.Request, endpoint string, out interface{}) (*http.Response, error) {
 	resp, err := c.Client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	fmt.Println(err)
+	return resp, decodeResponse(resp, endpoint, out)
+}
 
-	body, err = ioutil.ReadAll(resp.Body)
+// decodeResponse is the single place where server responses are interpreted.
+// A 2xx body is unmarshalled into out (unless out is nil), anything else is
+// turned into one of the typed errors from errors.go.
+func decodeResponse(resp *http.Response, endpoint string, out interface{}) error {
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return fmt.Errorf("reading %s response: %w", endpoint, err)
 	}
 
-	switch resp.StatusCode {
-	case 200:
-		token := resp.Header.Get("X-Auth-Token")
-		c.Token = token
-		fmt.Println(token)
-		return token, nil
-	case 400:
-		var errResp BadRequestError
-		json.Unmarshal(body, &errResp)
-		return "", errResp
-	case 403:
-		var errResp BadRequestError
-		json.Unmarshal(body, &errResp)
-		return "", errResp
-	default:
-		return "", errors.New("unexpected API error")
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newAPIError(resp.StatusCode, endpoint, resp.Header, body)
+	}
+	if out == nil || len(body) == 0 {
+		return nil
 	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding %s response: %w", endpoint, err)
+	}
+	return nil
 }
 
-func (c *Client) GetGameBoard() (*GameBoard, error) {
-	req, err := http.NewRequest(http.MethodGet, c.BaseURL+BoardURL, nil)
+func (c *Client) GetGameStatus(ctx context.Context) (GameStatus, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, GameURL, nil)
 	if err != nil {
-		return nil, err
+		return GameStatus{}, err
 	}
-	req.Header.Set("X-Auth-Token", c.Token)
 
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return nil, err
+	var gameStatus GameStatus
+	if _, err := c.do(req, GameURL, &gameStatus); err != nil {
+		return GameStatus{}, err
 	}
-	defer resp.Body.Close()
+	return gameStatus, nil
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
+func (c *Client) StartGame(ctx context.Context, nick, desc, targetNick string, coords []string, botGame bool) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, GameURL, StartGameData{
+		Coords:     coords,
+		Desc:       desc,
+		Nick:       nick,
+		TargetNick: targetNick,
+		WPBot:      botGame,
+	})
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	switch resp.StatusCode {
-	case 200:
-		var gameBoard GameBoard
-		if err := json.Unmarshal(body, &gameBoard); err != nil {
-			return nil, err
-		}
-		return &gameBoard, nil
-	case 401:
-		var errResp UnauthorizedError
-		json.Unmarshal(body, &errResp)
-		return nil, errResp
-	case 403:
-		var errResp ForbiddenError
-		json.Unmarshal(body, &errResp)
-		return nil, errResp
-	default:
-		return nil, errors.New("unexpected API error")
+	resp, err := c.do(req, GameURL, nil)
+	if err != nil {
+		return "", err
 	}
+	c.Token = resp.Header.Get("X-Auth-Token")
+	return c.Token, nil
 }
-func (c *Client) Fire(data FireData) (FireResult, error) {
 
-	bodyBytes, err := json.Marshal(data)
+func (c *Client) GetGameBoard(ctx context.Context) (*GameBoard, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, BoardURL, nil)
 	if err != nil {
-		return FireResult{}, err
+		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.BaseURL+FireURL, bytes.NewBuffer(bodyBytes))
-	if err != nil {
-		return FireResult{}, err
+	var gameBoard GameBoard
+	if _, err := c.do(req, BoardURL, &gameBoard); err != nil {
+		return nil, err
 	}
-	req.Header.Set("X-Auth-Token", c.Token)
-	req.Header.Set("Content-Type", "application/json")
+	return &gameBoard, nil
+}
 
-	resp, err := c.Client.Do(req)
+func (c *Client) Fire(ctx context.Context, data FireData) (FireResult, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, FireURL, data)
 	if err != nil {
 		return FireResult{}, err
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return FireResult{}, err
-	}
-	switch resp.StatusCode {
-	case 200:
-		var fireResult FireResult
-		if err := json.Unmarshal(body, &fireResult); err != nil {
-			return FireResult{}, err
-		}
-		return fireResult, err
-	case 400:
-		var errResp BadRequestError
-		json.Unmarshal(body, &errResp)
-		return FireResult{}, err
-	case 401:
-		var errResp UnauthorizedError
-		json.Unmarshal(body, &errResp)
-		return FireResult{}, err
-	case 403:
-		var errResp ForbiddenError
-		json.Unmarshal(body, &errResp)
+	var fireResult FireResult
+	if _, err := c.do(req, FireURL, &fireResult); err != nil {
 		return FireResult{}, err
-	case 429:
-		var errResp RateLimitExceededError
-		json.Unmarshal(body, &errResp)
-		return FireResult{}, err
-	default:
-		return FireResult{}, errors.New("unexpected API error")
 	}
+	return fireResult, nil
 }
-func (c *Client) AbandonGame() error {
-	req, err := http.NewRequest(http.MethodDelete, c.BaseURL+AbandonGameURL, nil)
-	if err != nil {
-		return err
-	}
-	req.Header.Set("X-Auth-Token", c.Token)
 
-	resp, err := c.Client.Do(req)
+func (c *Client) AbandonGame(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, AbandonGameURL, nil)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
-	switch resp.StatusCode {
-	case 200:
-		return nil
-	case 400:
-		var errResp BadRequestError
-		json.Unmarshal(body, &errResp)
-		return errResp
-	case 401:
-		var errResp UnauthorizedError
-		json.Unmarshal(body, &errResp)
-		return errResp
-	case 403:
-		var errResp ForbiddenError
-		json.Unmarshal(body, &errResp)
-		return errResp
-	default:
-		return errors.New("unexpected API error")
-	}
+	_, err = c.do(req, AbandonGameURL, nil)
+	return err
 }
-func (c *Client) GetGameDescription() (GameDescription, error) {
-	req, err := http.NewRequest(http.MethodGet, c.BaseURL+GameDescURL, nil)
-	if err != nil {
-		return GameDescription{}, err
-	}
-	req.Header.Set("X-Auth-Token", c.Token)
 
-	resp, err := c.Client.Do(req)
+func (c *Client) GetGameDescription(ctx context.Context) (GameDescription, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, GameDescURL, nil)
 	if err != nil {
 		return GameDescription{}, err
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
+	var gameDescription GameDescription
+	if _, err := c.do(req, GameDescURL, &gameDescription); err != nil {
 		return GameDescription{}, err
 	}
-
-	switch resp.StatusCode {
-	case 200:
-		var gameDescription GameDescription
-		if err := json.Unmarshal(body, &gameDescription); err != nil {
-			return GameDescription{}, err
-		}
-
-		return gameDescription, nil
-	case 401:
-		var errResp UnauthorizedError
-		json.Unmarshal(body, &errResp)
-		return GameDescription{}, err
-	case 404:
-		var errResp NotFoundError
-		json.Unmarshal(body, &errResp)
-		return GameDescription{}, err
-	case 429:
-		var errResp RateLimitExceededError
-		json.Unmarshal(body, &errResp)
-		return GameDescription{}, err
-	default:
-		return GameDescription{}, errors.New("unexpected API error")
-	}
+	return gameDescription, nil
 }
-func (c *Client) RefreshGameSession() error {
-	req, err := http.NewRequest(http.MethodGet, c.BaseURL+RefreshURL, nil)
-	if err != nil {
-		return err
-	}
-	req.Header.Set("X-Auth-Token", c.Token)
-
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+func (c *Client) RefreshGameSession(ctx context.Context) error {
+	req, err := c.newRequest(ctx, http.MethodGet, RefreshURL, nil)
 	if err != nil {
 		return err
 	}
 
-	switch resp.StatusCode {
-	case 200:
-		return nil
-	case 400:
-		var errResp BadRequestError
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			return err
-		}
-		return errResp
-	case 401:
-		var errResp UnauthorizedError
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			return err
-		}
-		return errResp
-	case 403:
-		var errResp ForbiddenError
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			return err
-		}
-		return errResp
-	case 429:
-		var errResp RateLimitExceededError
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			return err
-		}
-		return errResp
-	default:
-		return errors.New("unexpected API error")
-	}
+	_, err = c.do(req, RefreshURL, nil)
+	return err
 }
-func (c *Client) GetAllGames(status string) (GameList, error) {
-	var gameList GameList
 
-	req, err := http.NewRequest(http.MethodGet, c.BaseURL+"/list", nil)
+func (c *Client) GetAllGames(ctx context.Context, status string) (GameList, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, ListURL, nil)
 	if err != nil {
-		return gameList, err
+		return nil, err
 	}
 
 	q := req.URL.Query()
 	q.Add("status", status)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.Client.Do(req)
-	if err != nil {
-		return gameList, err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return gameList, err
-	}
-
-	switch resp.StatusCode {
-	case 200:
-		if err := json.Unmarshal(body, &gameList); err != nil {
-			return gameList, err
-		}
-		return gameList, nil
-	case 400:
-		var errResp BadRequestError
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			return gameList, err
-		}
-		return gameList, errResp
-	default:
-		return gameList, errors.New("unexpected API error")
+	var gameList GameList
+	if _, err := c.do(req, ListURL, &gameList); err != nil {
+		return nil, err
 	}
+	return gameList, nil
 }
 
 // GetLobbyPlayers retrieves a list of players waiting in the lobby
-func (c *Client) GetLobbyPlayers() ([]LobbyPlayer, error) {
-	var players []LobbyPlayer
-
-	req, err := http.NewRequest(http.MethodGet, c.BaseURL+"/lobby", nil)
-	if err != nil {
-		return players, err
-	}
-
-	resp, err := c.Client.Do(req)
+func (c *Client) GetLobbyPlayers(ctx context.Context) ([]LobbyPlayer, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, LobbyURL, nil)
 	if err != nil {
-		return players, err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return players, err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		var errResp BadRequestError
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			return players, err
-		}
-		return players, errResp
+		return nil, err
 	}
 
-	if err := json.Unmarshal(body, &players); err != nil {
-		return players, err
+	var players []LobbyPlayer
+	if _, err := c.do(req, LobbyURL, &players); err != nil {
+		return nil, err
 	}
-
 	return players, nil
 }
 
 // GetTopPlayerStats retrieves top 10 players' statistics
-func (c *Client) GetTopPlayerStats() (TopPlayerStats, error) {
-	var topStats TopPlayerStats
-
-	req, err := http.NewRequest(http.MethodGet, c.BaseURL+"/stats", nil)
-	if err != nil {
-		return topStats, err
-	}
-
-	resp, err := c.Client.Do(req)
+func (c *Client) GetTopPlayerStats(ctx context.Context) (TopPlayerStats, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, StatsURL, nil)
 	if err != nil {
-		return topStats, err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return topStats, err
+		return TopPlayerStats{}, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		var errResp BadRequestError
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			return topStats, err
-		}
-		return topStats, errResp
-	}
-
-	if err := json.Unmarshal(body, &topStats); err != nil {
-		return topStats, err
+	var topStats TopPlayerStats
+	if _, err := c.do(req, StatsURL, &topStats); err != nil {
+		return TopPlayerStats{}, err
 	}
-
 	return topStats, nil
 }
 
-func (c *Client) GetPlayerStats(nick string) (GameStats, error) {
-	url := "https://go-pjatk-server.fly.dev/api/stats/" + strings.TrimSpace(nick)
-
-	// Create the HTTP request
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %v", err)
-	}
-
-	// Set the request headers
-	req.Header.Set("Content-Type", "application/json")
+// GetPlayerStats retrieves statistics of a single player
+func (c *Client) GetPlayerStats(ctx context.Context, nick string) (GameStats, error) {
+	endpoint := StatsURL + "/" + strings.TrimSpace(nick)
 
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	req, err := c.newRequest(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error sending request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Check the response status code
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected response status code: %d", resp.StatusCode)
+		return nil, err
 	}
 
-	// Read the response body
 	var response struct {
 		Stats GameStat `json:"stats"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&response)
-	if err != nil {
-		fmt.Println("Error:", err)
+	if _, err := c.do(req, endpoint, &response); err != nil {
 		return nil, err
 	}
-
 	return GameStats{response.Stats}, nil
 }
 
-func (c *Client) AbortGame() error {
-	url := "https://go-pjatk-server.fly.dev/api/game/abandon"
-
-	// Create the HTTP request
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		fmt.Println("Error creating HTTP request:", err)
-		return err
-	}
-	req.Header.Set("X-Auth-Token", c.Token)
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-
-	if err != nil {
-		fmt.Println("Error sending HTTP request:", err)
-		return err
-	}
-	defer resp.Body.Close()
-
-	// Check the response status code
-	if resp.StatusCode != http.StatusOK {
-		fmt.Println("Unexpected response:", resp.Status)
-		return fmt.Errorf("unexpected response: %s", resp.Status)
-	}
-
-	return nil
+// AbortGame abandons the current game, it is an alias of AbandonGame
+func (c *Client) AbortGame(ctx context.Context) error {
+	return c.AbandonGame(ctx)
 }
@@ -0,0 +1,208 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/api/apitest"
+)
+
+// layout is a classic fleet used by both sides
+var layout = []string{
+	"A1", "A2", "A3", "A4",
+	"A6", "A7", "A8", "C1", "C2", "C3",
+	"C5", "C6", "C8", "C9", "E1", "E2",
+	"E4", "E6", "E8", "E10",
+}
+
+// newServer starts a fake server whose bot has the ships of layout and fires shots first
+func newServer(t *testing.T, shots ...string) *apitest.Server {
+	t.Helper()
+	srv := apitest.NewServer(apitest.Config{
+		Opponent: func() apitest.Opponent {
+			return apitest.ScriptedOpponent{Ships: layout, Shots: shots}
+		},
+	})
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// startBotGame starts a bot game on srv with a client using policy p
+func startBotGame(t *testing.T, srv *apitest.Server, p api.RetryPolicy) *api.Client {
+	t.Helper()
+	c := srv.Client(api.WithRetryPolicy(p))
+	if _, err := c.StartGame(context.Background(), "tester", "", "", layout, true); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		status   int
+		sentinel error
+		is       func(error) bool
+		as       func(error) bool
+	}{
+		{http.StatusBadRequest, api.ErrBadRequest, api.IsBadRequest, asType[api.BadRequestError]},
+		{http.StatusUnauthorized, api.ErrUnauthorized, api.IsUnauthorized, asType[api.UnauthorizedError]},
+		{http.StatusForbidden, api.ErrForbidden, api.IsForbidden, asType[api.ForbiddenError]},
+		{http.StatusNotFound, api.ErrNotFound, api.IsNotFound, asType[api.NotFoundError]},
+		{http.StatusTooManyRequests, api.ErrRateLimited, api.IsRateLimited, asType[api.RateLimitExceededError]},
+		{http.StatusInternalServerError, api.ErrServer, api.IsServerError, asType[api.ServerError]},
+		{http.StatusServiceUnavailable, api.ErrServer, api.IsServerError, asType[api.ServerError]},
+		{http.StatusTeapot, nil, nil, asType[api.ApiError]},
+	}
+	sentinels := []error{api.ErrBadRequest, api.ErrUnauthorized, api.ErrForbidden, api.ErrNotFound, api.ErrRateLimited, api.ErrServer}
+
+	srv := newServer(t)
+	c := startBotGame(t, srv, api.NoRetry())
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			srv.Inject(apitest.Fault{Path: api.GameURL, Status: tt.status, Message: "injected"})
+			_, err := c.GetGameStatus(context.Background())
+			if err == nil {
+				t.Fatal("no error")
+			}
+			for _, s := range sentinels {
+				if got, want := errors.Is(err, s), s == tt.sentinel; got != want {
+					t.Errorf("errors.Is(%v) = %v, want %v", s, got, want)
+				}
+			}
+			if tt.is != nil && !tt.is(err) {
+				t.Error("helper does not match")
+			}
+			if !tt.as(err) {
+				t.Errorf("unexpected error type %T", err)
+			}
+			e, ok := api.AsAPIError(err)
+			if !ok {
+				t.Fatal("not an API error")
+			}
+			if e.StatusCode != tt.status || api.StatusCode(err) != tt.status {
+				t.Errorf("status code %d, want %d", e.StatusCode, tt.status)
+			}
+			if e.Message != "injected" || e.Endpoint != api.GameURL {
+				t.Errorf("got message %q on %q", e.Message, e.Endpoint)
+			}
+		})
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	srv := newServer(t)
+	c := startBotGame(t, srv, api.NoRetry())
+	srv.Inject(apitest.Fault{Path: api.GameURL, Status: http.StatusTooManyRequests, RetryAfter: 3 * time.Second})
+	_, err := c.GetGameStatus(context.Background())
+	if e, ok := api.AsAPIError(err); !ok || e.RetryAfter != 3*time.Second {
+		t.Errorf("got %v, want a Retry-After of 3s", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	fast := api.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+		MaxElapsed:     time.Second,
+	}
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		times    int
+		wantErr  func(error) bool
+		requests int
+	}{
+		{"status retried after 503", api.GameURL, http.StatusServiceUnavailable, 1, nil, 2},
+		{"status retried after 429", api.GameURL, http.StatusTooManyRequests, 2, nil, 3},
+		{"status gives up after max attempts", api.GameURL, http.StatusInternalServerError, 5, api.IsServerError, 3},
+		{"status not retried after 401", api.GameURL, http.StatusUnauthorized, 1, api.IsUnauthorized, 1},
+		{"status not retried after 403", api.GameURL, http.StatusForbidden, 1, api.IsForbidden, 1},
+		{"fire retried after 429", api.FireURL, http.StatusTooManyRequests, 1, nil, 2},
+		{"fire not retried after 503", api.FireURL, http.StatusServiceUnavailable, 1, api.IsServerError, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t)
+			c := startBotGame(t, srv, fast)
+			before := srv.Requests(tt.path)
+			srv.Inject(apitest.Fault{Path: tt.path, Status: tt.status, Times: tt.times})
+
+			var err error
+			if tt.path == api.FireURL {
+				_, err = c.Fire(context.Background(), api.FireData{Coord: "A1"})
+			} else {
+				_, err = c.GetGameStatus(context.Background())
+			}
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != nil && !tt.wantErr(err):
+				t.Errorf("got error %v", err)
+			}
+			if n := srv.Requests(tt.path) - before; n != tt.requests {
+				t.Errorf("%d request(s), want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxElapsed time.Duration
+		wantErr    bool
+		requests   int
+	}{
+		{"waits beyond max backoff", 3 * time.Second, false, 2},
+		{"gives up beyond max elapsed", 500 * time.Millisecond, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := api.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, MaxElapsed: tt.maxElapsed}
+			srv := newServer(t)
+			c := startBotGame(t, srv, p)
+			before := srv.Requests(api.GameURL)
+			srv.Inject(apitest.Fault{Path: api.GameURL, Status: http.StatusTooManyRequests, RetryAfter: time.Second})
+
+			start := time.Now()
+			_, err := c.GetGameStatus(context.Background())
+			elapsed := time.Since(start)
+			if tt.wantErr != api.IsRateLimited(err) || !tt.wantErr && err != nil {
+				t.Fatalf("got error %v", err)
+			}
+			if n := srv.Requests(api.GameURL) - before; n != tt.requests {
+				t.Errorf("%d request(s), want %d", n, tt.requests)
+			}
+			if tt.wantErr && elapsed > 500*time.Millisecond || !tt.wantErr && elapsed < time.Second {
+				t.Errorf("took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	srv := newServer(t)
+	c := startBotGame(t, srv, api.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})
+	before := srv.Requests(api.GameURL)
+	srv.FailNext(api.GameURL, http.StatusServiceUnavailable)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetGameStatus(ctx); !api.IsServerError(err) {
+		t.Errorf("got error %v, want the last server error", err)
+	}
+	if n := srv.Requests(api.GameURL) - before; n != 1 {
+		t.Errorf("%d request(s), want 1", n)
+	}
+}
+
+func asType[T error](err error) bool {
+	var target T
+	return errors.As(err, &target)
+}
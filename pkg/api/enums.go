@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"warships/pkg/state"
+)
+
+// GamePhase is the game_status reported by the server
+type GamePhase string
+
+const (
+	PhaseNoGame     GamePhase = "no_game"
+	PhaseWaiting    GamePhase = "waiting"
+	PhaseWaitingBot GamePhase = "waiting_wpbot"
+	PhaseInProgress GamePhase = "game_in_progress"
+	PhaseEnded      GamePhase = "ended"
+)
+
+// GameOutcome is the last_game_status reported by the server
+type GameOutcome string
+
+const (
+	// OutcomeNone is used when the server does not report an outcome at all
+	OutcomeNone   GameOutcome = ""
+	OutcomeNoGame GameOutcome = "no_game"
+	OutcomeWin    GameOutcome = "win"
+	OutcomeLose   GameOutcome = "lose"
+)
+
+// ShotResult is the result of a shot
+type ShotResult string
+
+const (
+	ShotHit  ShotResult = "hit"
+	ShotMiss ShotResult = "miss"
+	ShotSunk ShotResult = "sunk"
+)
+
+// UnknownValueError is returned when the server sends a value the client does not know
+type UnknownValueError struct {
+	Type  string
+	Value string
+}
+
+func (e UnknownValueError) Error() string {
+	return fmt.Sprintf("unknown %s %q", e.Type, e.Value)
+}
+
+// Waiting reports whether the session sits in the lobby
+func (p GamePhase) Waiting() bool {
+	return p == PhaseWaiting || p == PhaseWaitingBot
+}
+
+func (p GamePhase) valid() bool {
+	switch p {
+	case PhaseNoGame, PhaseWaiting, PhaseWaitingBot, PhaseInProgress, PhaseEnded:
+		return true
+	}
+	return false
+}
+
+func (p *GamePhase) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(b, "game phase", p, GamePhase.valid)
+}
+
+func (o GameOutcome) valid() bool {
+	switch o {
+	case OutcomeNone, OutcomeNoGame, OutcomeWin, OutcomeLose:
+		return true
+	}
+	return false
+}
+
+func (o *GameOutcome) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(b, "game outcome", o, GameOutcome.valid)
+}
+
+// IsHit reports whether the shot hit a ship, sinking it or not
+func (r ShotResult) IsHit() bool {
+	return r == ShotHit || r == ShotSunk
+}
+
+// Cell returns the board mark for the result
+func (r ShotResult) Cell() state.Cell {
+	switch r {
+	case ShotHit:
+		return state.Hit
+	case ShotSunk:
+		return state.Sunk
+	default:
+		return state.Miss
+	}
+}
+
+func (r ShotResult) valid() bool {
+	switch r {
+	case ShotHit, ShotMiss, ShotSunk:
+		return true
+	}
+	return false
+}
+
+func (r *ShotResult) UnmarshalJSON(b []byte) error {
+	return unmarshalEnum(b, "shot result", r, ShotResult.valid)
+}
+
+// ParseShotResult converts a string to a ShotResult, rejecting unknown values
+func ParseShotResult(s string) (ShotResult, error) {
+	r := ShotResult(s)
+	if !r.valid() {
+		return "", UnknownValueError{Type: "shot result", Value: s}
+	}
+	return r, nil
+}
+
+func unmarshalEnum[T ~string](b []byte, name string, v *T, valid func(T) bool) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if !valid(T(s)) {
+		return UnknownValueError{Type: name, Value: s}
+	}
+	*v = T(s)
+	return nil
+}
@@ -1,30 +1,176 @@
 package api
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Sentinel errors matched by the typed API errors, so callers can use
+// errors.Is without caring about the concrete type.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limit exceeded")
+	ErrServer       = errors.New("server error")
+)
+
 type ErrorMessage struct {
 	Message string `json:"message"`
 }
 
+// ApiError describes a non-2xx response returned by the server
 type ApiError struct {
 	ErrorMessage
-	ErrorType string
+	ErrorType  string
+	StatusCode int
+	Endpoint   string
+	// RetryAfter is the wait requested by the server through the Retry-After header
+	RetryAfter time.Duration
 }
 
+// UnauthorizedError is returned for 401 responses, usually an expired session
 type UnauthorizedError struct {
 	ApiError
 }
+
+// ForbiddenError is returned for 403 responses, e.g. firing out of turn
 type ForbiddenError struct {
 	ApiError
 }
+
+// RateLimitExceededError is returned for 429 responses
 type RateLimitExceededError struct {
 	ApiError
 }
+
+// BadRequestError is returned for 400 responses
 type BadRequestError struct {
 	ApiError
 }
+
+// NotFoundError is returned for 404 responses
 type NotFoundError struct {
 	ApiError
 }
 
+// ServerError is returned for 5xx responses
+type ServerError struct {
+	ApiError
+}
+
 func (e ApiError) Error() string {
-	return e.ErrorType + ": " + e.Message
+	msg := fmt.Sprintf("%s (%d) on %s", e.ErrorType, e.StatusCode, e.Endpoint)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e ApiError) apiError() ApiError {
+	return e
+}
+
+func (e UnauthorizedError) Is(target error) bool      { return target == ErrUnauthorized }
+func (e ForbiddenError) Is(target error) bool         { return target == ErrForbidden }
+func (e RateLimitExceededError) Is(target error) bool { return target == ErrRateLimited }
+func (e BadRequestError) Is(target error) bool        { return target == ErrBadRequest }
+func (e NotFoundError) Is(target error) bool          { return target == ErrNotFound }
+func (e ServerError) Is(target error) bool            { return target == ErrServer }
+
+// newAPIError builds the typed error matching the response status code
+func newAPIError(statusCode int, endpoint string, header http.Header, body []byte) error {
+	var msg ErrorMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		msg.Message = strings.TrimSpace(string(body))
+	}
+	base := ApiError{
+		ErrorMessage: msg,
+		StatusCode:   statusCode,
+		Endpoint:     endpoint,
+		RetryAfter:   parseRetryAfter(header.Get("Retry-After")),
+	}
+
+	switch {
+	case statusCode == 400:
+		base.ErrorType = "bad request"
+		return BadRequestError{base}
+	case statusCode == 401:
+		base.ErrorType = "unauthorized"
+		return UnauthorizedError{base}
+	case statusCode == 403:
+		base.ErrorType = "forbidden"
+		return ForbiddenError{base}
+	case statusCode == 404:
+		base.ErrorType = "not found"
+		return NotFoundError{base}
+	case statusCode == 429:
+		base.ErrorType = "rate limit exceeded"
+		return RateLimitExceededError{base}
+	case statusCode >= 500:
+		base.ErrorType = "server error"
+		return ServerError{base}
+	default:
+		base.ErrorType = "unexpected API error"
+		return base
+	}
+}
+
+// AsAPIError returns the ApiError carried by err, whatever its concrete type
+func AsAPIError(err error) (ApiError, bool) {
+	var e interface{ apiError() ApiError }
+	if errors.As(err, &e) {
+		return e.apiError(), true
+	}
+	return ApiError{}, false
+}
+
+// StatusCode returns the HTTP status code carried by err, or 0 if err is not an API error
+func StatusCode(err error) int {
+	if e, ok := AsAPIError(err); ok {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// IsBadRequest reports whether err is a 400 response
+func IsBadRequest(err error) bool {
+	return errors.Is(err, ErrBadRequest)
+}
+
+// IsUnauthorized reports whether err is a 401 response, i.e. the session is gone
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsForbidden reports whether err is a 403 response
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+// IsNotFound reports whether err is a 404 response
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsRateLimited reports whether err is a 429 response
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsServerError reports whether err is a 5xx response
+func IsServerError(err error) bool {
+	return errors.Is(err, ErrServer)
+}
+
+// IsUnavailable reports whether the server could not be reached or failed with a 5xx
+func IsUnavailable(err error) bool {
+	var urlErr *url.Error
+	return IsServerError(err) || errors.As(err, &urlErr)
 }
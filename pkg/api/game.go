@@ -1,9 +1,12 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strconv"
+	"sync"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
 	"warships/pkg/state"
 )
 
@@ -14,70 +17,166 @@ var (
 type Game struct {
 	client *Client
 	state  *state.GameState
+	mu     sync.Mutex
+	shots  []ShotRecord
 }
 
-// NewGame returns a new Game
-func NewGame() *Game {
+// NewGame returns a new classic Game, opts configure the underlying Client
+func NewGame(opts ...Option) *Game {
 	return &Game{
-		client: NewClient("https://go-pjatk-server.fly.dev/api", ""),
-		state:  state.NewGameState(),
+		client: NewClient(opts...),
+		state:  state.NewGameState(rules.Classic()),
 	}
 }
 
-func (g *Game) FireShot(coord string) (FireResult, int, error) {
-	result, err := g.client.Fire(FireData{
-		Coord: coord},
-	)
+// Config returns the variant being played
+func (g *Game) Config() rules.Config {
+	return g.state.Config()
+}
+
+// SetConfig switches to the variant cfg, the server must play the same variant
+func (g *Game) SetConfig(cfg rules.Config) error {
+	if err := cfg.Check(); err != nil {
+		return err
+	}
+	g.state.SetConfig(cfg)
+	return nil
+}
+
+func (g *Game) FireShot(ctx context.Context, target string) (FireResult, int, error) {
+	c, err := g.state.Config().Parse(target)
+	if err != nil {
+		return FireResult{}, 0, err
+	}
+	result, err := g.client.Fire(ctx, FireData{Coord: c.String()})
 	if err != nil {
 		return FireResult{}, 0, err
 	}
-	l := g.MarkOpponent(coord, result)
+	l := g.MarkOpponent(c.String(), result)
+	g.mu.Lock()
+	g.shots = append(g.shots, ShotRecord{Coord: c.String(), Result: result.Result})
+	g.mu.Unlock()
 	return result, l, err
 }
 
-// StartGame starts the game
-func (g *Game) StartGame(nick, desc, targetNick string, coords []string, botGame bool) {
-	_, err := g.client.StartGame(nick, desc, targetNick, coords, botGame)
+// Shots returns the shots fired by the player in the current game
+func (g *Game) Shots() []ShotRecord {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]ShotRecord(nil), g.shots...)
+}
+
+// Token returns the X-Auth-Token of the current game session
+func (g *Game) Token() string {
+	return g.client.Token
+}
+
+// ServerURL returns the base URL of the server the game is played on
+func (g *Game) ServerURL() string {
+	return g.client.BaseURL
+}
+
+// Scheduler returns a Scheduler owning the periodic calls of the current game session
+func (g *Game) Scheduler(cfg SchedulerConfig) *Scheduler {
+	return NewScheduler(g.client, cfg)
+}
+
+// Snapshot returns a copy of the local game state
+func (g *Game) Snapshot() state.Snapshot {
+	return g.state.Snapshot()
+}
+
+// Resume reattaches to a running game session. The player board is loaded
+// from the server and the opponent board is rebuilt by replaying shots.
+func (g *Game) Resume(ctx context.Context, token string, snap state.Snapshot, shots []ShotRecord) (GameStatus, error) {
+	g.client.Token = token
+	status, err := g.client.GetGameStatus(ctx)
 	if err != nil {
-		return
+		return GameStatus{}, err
+	}
+	if status.GameStatus != PhaseInProgress && !status.GameStatus.Waiting() {
+		return status, fmt.Errorf("game is no longer running: %s", status.GameStatus)
+	}
+	board, err := g.client.GetGameBoard(ctx)
+	if err != nil {
+		return GameStatus{}, err
+	}
+
+	g.state.Restore(state.Snapshot{
+		Player:         snap.Player,
+		Opponent:       snap.Opponent,
+		Config:         snap.Config,
+		LastGameStatus: snap.LastGameStatus,
+	})
+	if _, err := g.SetPlayerBoard(board.Board); err != nil {
+		return GameStatus{}, err
+	}
+	g.MarkOpponentShots(status.OppShots)
+
+	g.mu.Lock()
+	g.shots = nil
+	g.mu.Unlock()
+	for _, shot := range shots {
+		g.MarkOpponent(shot.Coord, FireResult{Result: shot.Result})
+		g.mu.Lock()
+		g.shots = append(g.shots, shot)
+		g.mu.Unlock()
+	}
+	return status, nil
+}
+
+// StartGame starts the game
+func (g *Game) StartGame(ctx context.Context, nick, desc, targetNick string, coords []string, botGame bool) error {
+	if _, err := g.client.StartGame(ctx, nick, desc, targetNick, coords, botGame); err != nil {
+		return err
 	}
+	g.ClearState()
+	return nil
 }
-func (g *Game) GetGameStatus() (GameStatus, error) {
-	gameState, err := g.client.GetGameStatus()
+func (g *Game) GetGameStatus(ctx context.Context) (GameStatus, error) {
+	gameState, err := g.client.GetGameStatus(ctx)
 	if err != nil {
 		return GameStatus{}, err
 	}
 
 	return gameState, nil
 }
-func (g *Game) SetPlayerBoard(coords []string) ([10][10]string, error) {
-
-	board, err := g.state.UpdatePlayerBoard(setStatesFromCoords(coords, state.Ship))
+func (g *Game) SetPlayerBoard(coords []string) (state.Grid, error) {
+	states, err := setStatesFromCoords(g.state.Config(), coords, state.Ship)
 	if err != nil {
-		return [10][10]string{}, err
+		return nil, err
+	}
+	board, err := g.state.UpdatePlayerBoard(states)
+	if err != nil {
+		return nil, err
 
 	}
 	return board, nil
 }
 
-func (g *Game) GetDescription() (GameDescription, error) {
-	return g.client.GetGameDescription()
+func (g *Game) GetDescription(ctx context.Context) (GameDescription, error) {
+	return g.client.GetGameDescription(ctx)
 }
 
-func (g *Game) LoadPlayerBoard() (*GameBoard, error) {
-	return g.client.GetGameBoard()
+func (g *Game) LoadPlayerBoard(ctx context.Context) (*GameBoard, error) {
+	return g.client.GetGameBoard(ctx)
 }
 
 func (g *Game) UpdateGameState(nick string, desc string, opponent string, oppDesc string) {
 	g.state.UpdateGameState(nick, desc, opponent, oppDesc)
 }
-func (g *Game) GetPlayerBoard() [10][10]string {
+func (g *Game) GetPlayerBoard() state.Grid {
 	return g.state.GetPlayerBoard()
 }
+
+// MarkOpponentShots marks the shots of the opponent on the player board, malformed ones are skipped
 func (g *Game) MarkOpponentShots(shots []string) {
-	for _, coord := range shots {
-		x, y := mapToState(coord)
-		g.state.MarkPlayerBoard(x, y)
+	for _, shot := range shots {
+		c, err := g.state.Config().Parse(shot)
+		if err != nil {
+			continue
+		}
+		g.state.MarkPlayerBoard(c.Index())
 	}
 }
 
@@ -85,27 +184,19 @@ func (g *Game) GetGameState() (*state.GameState, error) {
 	return g.state.GetGameState(), nil
 }
 
-func (g *Game) GetOpponentBoard() [10][10]string {
+func (g *Game) GetOpponentBoard() state.Grid {
 	return g.state.GetOpponentBoard()
 
 }
 
 func (g *Game) MarkOpponent(shot string, result FireResult) int {
-	if shot == "" {
+	c, err := g.state.Config().Parse(shot)
+	if err != nil {
 		return 0
 	}
-	x, y := mapToState(shot)
-	var mark string
-	switch result.Result {
-	case "sunk":
-		mark = state.Sunk
-	case "hit":
-		mark = state.Hit
-	case "miss":
-		mark = state.Miss
-	}
-	g.state.IncreaseHits(result.Result)
-	return g.state.MarkOpponentBoard(x, y, mark)
+	x, y := c.Index()
+	g.state.IncreaseHits(result.Result.IsHit())
+	return g.state.MarkOpponentBoard(x, y, result.Result.Cell())
 }
 
 func (g *Game) UpdatePlayerInfo(name string, description string) {
@@ -120,17 +211,20 @@ func (g *Game) UpdatePlayersDesc(d GameDescription) {
 	g.state.UpdatePlayersDesc(d.Desc, d.OppDesc)
 }
 
-func (g *Game) GetTopPlayerStats() (TopPlayerStats, error) {
-	stats, err := g.client.GetTopPlayerStats()
+func (g *Game) GetTopPlayerStats(ctx context.Context) (TopPlayerStats, error) {
+	stats, err := g.client.GetTopPlayerStats(ctx)
 	if err != nil {
 		return TopPlayerStats{}, err
 	}
 	return stats, nil
 }
 
-func (g *Game) MarkPlayerShip(coords string) {
-	x, y := mapToState(coords)
-	g.state.AddShip(x, y)
+func (g *Game) MarkPlayerShip(ship string) {
+	c, err := g.state.Config().Parse(ship)
+	if err != nil {
+		return
+	}
+	g.state.AddShip(c.Index())
 }
 
 func (g *Game) GetPlayerCoords() []string {
@@ -140,41 +234,30 @@ func (g *Game) GetPlayerCoords() []string {
 	for i, row := range states {
 		for j, s := range row {
 			if s == state.Ship {
-				coords = append(coords, mapFromState(i, j))
+				coords = append(coords, coord.FromIndex(i, j).String())
 			}
 		}
 	}
-
-	// Ensure coordinates range from A1 to J10
-	var fixedCoords []string
-	for _, coord := range coords {
-		if isValidCoord(coord) {
-			fixedCoords = append(fixedCoords, coord)
-		}
-	}
-
-	return fixedCoords
+	return coords
 }
 
-func (g *Game) GetPlayerStats(name string) GameStats {
-	stats, err := g.client.GetPlayerStats(name)
+func (g *Game) GetPlayerStats(ctx context.Context, name string) (GameStats, error) {
+	stats, err := g.client.GetPlayerStats(ctx, name)
 	if err != nil {
-		fmt.Errorf("error getting player stats: %v", err)
-		return GameStats{}
+		return GameStats{}, fmt.Errorf("error getting player stats: %w", err)
 	}
-	return stats
+	return stats, nil
 }
 
-func (g *Game) GetPlayerLobby() []LobbyPlayer {
-	lobby, err := g.client.GetLobbyPlayers()
-	if err != nil {
-		return []LobbyPlayer{}
-	}
-	return lobby
+func (g *Game) GetPlayerLobby(ctx context.Context) ([]LobbyPlayer, error) {
+	return g.client.GetLobbyPlayers(ctx)
 }
 
 func (g *Game) ClearState() {
 	g.state.ClearState()
+	g.mu.Lock()
+	g.shots = nil
+	g.mu.Unlock()
 }
 
 func (g *Game) UpdateLastGameStatus(status string) {
@@ -185,29 +268,6 @@ func (g *Game) LastGameStatus() string {
 	return g.state.LastGameStatus()
 }
 
-func (g *Game) AbortGame() {
-	g.client.AbortGame()
-}
-
-func mapFromState(x, y int) string {
-	return string(byte(x+65)) + strconv.Itoa(y+1)
-}
-
-func isValidCoord(coord string) bool {
-	if len(coord) < 2 || len(coord) > 3 {
-		return false
-	}
-
-	column := coord[0]
-	row := coord[1:]
-	if column < 'A' || column > 'J' {
-		return false
-	}
-
-	num, err := strconv.Atoi(row)
-	if err != nil || num < 1 || num > 10 {
-		return false
-	}
-
-	return true
+func (g *Game) AbortGame(ctx context.Context) error {
+	return g.client.AbortGame(ctx)
 }
@@ -0,0 +1,112 @@
+package api_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"warships/pkg/api"
+	"warships/pkg/api/apitest"
+	"warships/pkg/rules"
+)
+
+func TestResume(t *testing.T) {
+	tests := []struct {
+		name string
+		// end runs before the game is resumed
+		end     func(srv *apitest.Server, g *api.Game) error
+		wantErr func(error) bool
+	}{
+		{name: "running game"},
+		{
+			name: "expired session",
+			end: func(srv *apitest.Server, g *api.Game) error {
+				srv.Expire(g.Token())
+				return nil
+			},
+			wantErr: api.IsUnauthorized,
+		},
+		{
+			name: "abandoned game",
+			end: func(_ *apitest.Server, g *api.Game) error {
+				return g.AbortGame(context.Background())
+			},
+			wantErr: func(err error) bool { return err != nil && api.StatusCode(err) == 0 },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			srv := newServer(t, "A1", "J10")
+			g := api.NewGame(api.WithBaseURL(srv.BaseURL()), api.WithRetryPolicy(api.NoRetry()))
+			if err := g.StartGame(ctx, "tester", "", "", layout, true); err != nil {
+				t.Fatal(err)
+			}
+			board, err := g.LoadPlayerBoard(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := g.SetPlayerBoard(board.Board); err != nil {
+				t.Fatal(err)
+			}
+			// a hit keeps the turn, the miss lets the bot hit A1 and miss J10
+			for _, c := range []string{"A1", "A2", "B5"} {
+				if _, _, err := g.FireShot(ctx, c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			status, err := g.GetGameStatus(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.MarkOpponentShots(status.OppShots)
+			if tt.end != nil {
+				if err := tt.end(srv, g); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			resumed := api.NewGame(api.WithBaseURL(srv.BaseURL()), api.WithRetryPolicy(api.NoRetry()))
+			status, err = resumed.Resume(ctx, g.Token(), g.Snapshot(), g.Shots())
+			if tt.wantErr != nil {
+				if !tt.wantErr(err) {
+					t.Fatalf("got error %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.GameStatus != api.PhaseInProgress || !status.ShouldFire {
+				t.Errorf("got status %+v", status)
+			}
+			if want := []string{"A1", "J10"}; !reflect.DeepEqual(status.OppShots, want) {
+				t.Errorf("opponent shots %v, want %v", status.OppShots, want)
+			}
+			if !reflect.DeepEqual(resumed.Shots(), g.Shots()) {
+				t.Errorf("shots %v, want %v", resumed.Shots(), g.Shots())
+			}
+			if !reflect.DeepEqual(resumed.GetOpponentBoard(), g.GetOpponentBoard()) {
+				t.Error("the opponent board differs")
+			}
+			if !reflect.DeepEqual(resumed.GetPlayerBoard(), g.GetPlayerBoard()) {
+				t.Error("the player board differs")
+			}
+		})
+	}
+}
+
+func TestMarkOffBoard(t *testing.T) {
+	g := api.NewGame()
+	if err := g.SetConfig(rules.Training()); err != nil {
+		t.Fatal(err)
+	}
+	g.MarkPlayerShip("H8")
+	g.MarkPlayerShip("J10")
+	g.MarkOpponentShots([]string{"A1", "I1", "A9"})
+	if n := g.MarkOpponent("J10", api.FireResult{Result: api.ShotHit}); n != 0 {
+		t.Errorf("marked a shot off the board, got %d", n)
+	}
+	if got, want := g.GetPlayerCoords(), []string{"H8"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got ships %v, want %v", got, want)
+	}
+}
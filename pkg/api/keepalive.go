@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultKeepAliveInterval is well below the lobby timeout of the server
+const DefaultKeepAliveInterval = 10 * time.Second
+
+// KeepAlive refreshes a session while it waits in the lobby, so that it does
+// not expire before an opponent joins. Feed it every polled status through
+// Observe; it stops on its own once the game starts or ends.
+type KeepAlive struct {
+	scheduler *Scheduler
+	interval  time.Duration
+	// OnError is called with every failed refresh, e.g. to show it in the UI
+	OnError func(error)
+
+	mu      sync.Mutex
+	phase   GamePhase
+	changed chan struct{}
+}
+
+// NewKeepAlive returns a KeepAlive refreshing the session through s every interval
+func NewKeepAlive(s *Scheduler, interval time.Duration) *KeepAlive {
+	if interval <= 0 {
+		interval = DefaultKeepAliveInterval
+	}
+	return &KeepAlive{
+		scheduler: s,
+		interval:  interval,
+		changed:   make(chan struct{}, 1),
+	}
+}
+
+// Observe records the latest game status
+func (k *KeepAlive) Observe(status GameStatus) {
+	k.mu.Lock()
+	k.phase = status.GameStatus
+	k.mu.Unlock()
+
+	select {
+	case k.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Run refreshes the session until ctx is done, the game starts or ends, or the session is lost
+func (k *KeepAlive) Run(ctx context.Context) {
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-k.changed:
+			if k.finished() {
+				return
+			}
+		case <-ticker.C:
+			if k.finished() {
+				return
+			}
+			if !k.waiting() {
+				continue
+			}
+			err := k.scheduler.Refresh(ctx)
+			if err != nil && ctx.Err() == nil {
+				if k.OnError != nil {
+					k.OnError(err)
+				}
+				if IsUnauthorized(err) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (k *KeepAlive) waiting() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.phase.Waiting()
+}
+
+func (k *KeepAlive) finished() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.phase == PhaseInProgress || k.phase == PhaseEnded
+}
@@ -0,0 +1,63 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"warships/pkg/api"
+)
+
+func TestKeepAlive(t *testing.T) {
+	tests := []struct {
+		name string
+		// stop ends the keep-alive loop
+		stop func(k *api.KeepAlive, cancel context.CancelFunc)
+	}{
+		{"canceled", func(_ *api.KeepAlive, cancel context.CancelFunc) {
+			cancel()
+		}},
+		{"game started", func(k *api.KeepAlive, _ context.CancelFunc) {
+			k.Observe(api.GameStatus{GameStatus: api.PhaseInProgress})
+		}},
+		{"game ended", func(k *api.KeepAlive, _ context.CancelFunc) {
+			k.Observe(api.GameStatus{GameStatus: api.PhaseEnded})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			srv := newServer(t)
+			c := srv.Client(api.WithRetryPolicy(api.NoRetry()))
+			if _, err := c.StartGame(ctx, "host", "", "guest", layout, false); err != nil {
+				t.Fatal(err)
+			}
+
+			k := api.NewKeepAlive(api.NewScheduler(c, quick), 10*time.Millisecond)
+			k.OnError = func(err error) { t.Errorf("refresh: %v", err) }
+			done := make(chan struct{})
+			go func() {
+				k.Run(ctx)
+				close(done)
+			}()
+
+			// nothing is refreshed before the session is known to wait
+			time.Sleep(50 * time.Millisecond)
+			if n := srv.Requests(api.RefreshURL); n != 0 {
+				t.Errorf("%d refresh(es) before the lobby", n)
+			}
+			k.Observe(api.GameStatus{GameStatus: api.PhaseWaiting})
+			time.Sleep(50 * time.Millisecond)
+			if n := srv.Requests(api.RefreshURL); n < 2 {
+				t.Errorf("%d refresh(es) while waiting, want a few", n)
+			}
+
+			tt.stop(k, cancel)
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("still running")
+			}
+		})
+	}
+}
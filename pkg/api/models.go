@@ -3,16 +3,18 @@ package api
 import (
 	"fmt"
 	"sort"
+	"warships/pkg/rules"
+	"warships/pkg/state"
 )
 
 type GameStatus struct {
-	GameStatus     string   `json:"game_status"`
-	LastGameStatus string   `json:"last_game_status"`
-	Nick           string   `json:"nick"`
-	OppShots       []string `json:"opp_shots"`
-	Opponent       string   `json:"opponent"`
-	ShouldFire     bool     `json:"should_fire"`
-	Timer          int      `json:"timer"`
+	GameStatus     GamePhase   `json:"game_status"`
+	LastGameStatus GameOutcome `json:"last_game_status"`
+	Nick           string      `json:"nick"`
+	OppShots       []string    `json:"opp_shots"`
+	Opponent       string      `json:"opponent"`
+	ShouldFire     bool        `json:"should_fire"`
+	Timer          int         `json:"timer"`
 }
 type StartGameData struct {
 	Coords     []string `json:"coords"`
@@ -30,7 +32,7 @@ type FireData struct {
 }
 
 type FireResult struct {
-	Result string `json:"result"`
+	Result ShotResult `json:"result"`
 }
 type GameDescription struct {
 	Desc     string `json:"desc"`
@@ -41,14 +43,14 @@ type GameDescription struct {
 
 // GameList represents the list of games
 type GameList []struct {
-	Guest  string `json:"guest"`
-	Host   string `json:"host"`
-	ID     string `json:"id"`
-	Status string `json:"status"`
+	Guest  string    `json:"guest"`
+	Host   string    `json:"host"`
+	ID     string    `json:"id"`
+	Status GamePhase `json:"status"`
 }
 type LobbyPlayer struct {
-	GameStatus string `json:"game_status"`
-	Nick       string `json:"nick"`
+	GameStatus GamePhase `json:"game_status"`
+	Nick       string    `json:"nick"`
 }
 
 // PlayerStats represents statistics of a player
@@ -84,13 +86,20 @@ type Shot struct {
 	Coord string `json:"coord"`
 }
 
+// ShotRecord is a shot fired by the player together with its result
+type ShotRecord struct {
+	Coord  string     `json:"coord"`
+	Result ShotResult `json:"result"`
+}
+
 type GameState struct {
-	PlayerBoard  [10][10]string `json:"player_board"`
-	OppBoard     [10][10]string `json:"opp_board"`
-	TotalShots   int            `json:"total_shots"`
-	TotalHits    int            `json:"total_hits"`
-	PlayerDesc   string         `json:"player_desc"`
-	OppDesc      string         `json:"opp_desc"`
+	Config       rules.Config `json:"config"`
+	PlayerBoard  state.Grid   `json:"player_board"`
+	OppBoard     state.Grid   `json:"opp_board"`
+	TotalShots   int          `json:"total_shots"`
+	TotalHits    int          `json:"total_hits"`
+	PlayerDesc   string       `json:"player_desc"`
+	OppDesc      string       `json:"opp_desc"`
 	OppShipsSunk map[int]int
 }
 type GameStat struct {
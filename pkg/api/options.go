@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the public PJATK warships server
+	DefaultBaseURL = "https://go-pjatk-server.fly.dev/api"
+	// DefaultTimeout is the timeout of a single HTTP attempt
+	DefaultTimeout = 10 * time.Second
+	// DefaultUserAgent is sent with every request unless overridden
+	DefaultUserAgent = "wrshps"
+)
+
+// Option configures a Client created with NewClient
+type Option func(*Client)
+
+// WithBaseURL points the client at a different server, e.g. a local one
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.BaseURL = url
+	}
+}
+
+// WithToken sets the X-Auth-Token of an existing game session
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.Token = token
+	}
+}
+
+// WithHTTPClient replaces the underlying http.Client, nil is ignored. Later
+// options change a copy, so hc may be shared, e.g. http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.Client = hc
+		}
+	}
+}
+
+// WithTransport sets the transport of the underlying http.Client
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.ownClient().Transport = rt
+	}
+}
+
+// WithTimeout sets the timeout of a single HTTP attempt
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.ownClient().Timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.UserAgent = ua
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed calls
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.Retry = p
+	}
+}
+
+// ownClient replaces c.Client with a copy an option may change
+func (c *Client) ownClient() *http.Client {
+	var hc http.Client
+	if c.Client != nil {
+		hc = *c.Client
+	}
+	c.Client = &hc
+	return c.Client
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+type roundTripper struct{}
+
+func (roundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, http.ErrNotSupported
+}
+
+func TestHTTPClientOptions(t *testing.T) {
+	shared := &http.Client{Timeout: time.Minute}
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantTimeout   time.Duration
+		wantTransport http.RoundTripper
+	}{
+		{"defaults", nil, DefaultTimeout, nil},
+		{"nil client", []Option{WithHTTPClient(nil), WithTimeout(time.Second)}, time.Second, nil},
+		{"shared client", []Option{WithHTTPClient(shared)}, time.Minute, nil},
+		{"timeout on a shared client", []Option{WithHTTPClient(shared), WithTimeout(time.Second)}, time.Second, nil},
+		{"transport on the default client", []Option{WithHTTPClient(http.DefaultClient), WithTransport(roundTripper{})}, 0, roundTripper{}},
+		{"transport then client", []Option{WithTransport(roundTripper{}), WithHTTPClient(shared)}, time.Minute, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(tt.opts...)
+			if c.Client == nil {
+				t.Fatal("no http.Client")
+			}
+			if c.Client.Timeout != tt.wantTimeout || c.Client.Transport != tt.wantTransport {
+				t.Errorf("got timeout %v and transport %v", c.Client.Timeout, c.Client.Transport)
+			}
+			if shared.Timeout != time.Minute || shared.Transport != nil {
+				t.Error("the shared client changed")
+			}
+			if http.DefaultClient.Timeout != 0 || http.DefaultClient.Transport != nil {
+				t.Error("http.DefaultClient changed")
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how the Client retries failed calls.
+//
+// Idempotent calls (GETs such as GetGameStatus, GetGameBoard, GetGameDescription
+// or GetAllGames) are retried on 429, 5xx and transport errors. Non-idempotent
+// calls (Fire, StartGame) are only retried on 429, because the server rejected
+// them before doing anything; a 5xx or a dropped connection might mean the shot
+// was already fired, so those are returned to the caller as-is.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps a single wait. A longer Retry-After is still honoured
+	// unless it would exceed MaxElapsed, then the call gives up.
+	MaxBackoff time.Duration
+	// Multiplier grows the backoff after every attempt.
+	Multiplier float64
+	// Jitter is the fraction (0-1) of every wait that is randomised.
+	Jitter float64
+	// MaxElapsed caps the total time spent on one call, retries included.
+	MaxElapsed time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used by NewClient
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+		MaxElapsed:     15 * time.Second,
+	}
+}
+
+// NoRetry returns a policy that never retries
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// shouldRetry reports whether a call that failed with err may be sent again
+func (p RetryPolicy) shouldRetry(err error, idempotent bool) bool {
+	if IsRateLimited(err) {
+		return true
+	}
+	if !idempotent {
+		return false
+	}
+	var urlErr *url.Error
+	return IsServerError(err) || errors.As(err, &urlErr)
+}
+
+// backoff returns the wait before the given retry (1 for the first one)
+func (p RetryPolicy) backoff(retry int, err error) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(math.Max(p.Multiplier, 1), float64(retry-1))
+	if p.Jitter > 0 {
+		d -= d * p.Jitter * rand.Float64()
+	}
+	wait := time.Duration(d)
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+
+	// the server knows best, MaxElapsed decides whether the wait is worth it
+	if e, ok := AsAPIError(err); ok && e.RetryAfter > wait {
+		wait = e.RetryAfter
+	}
+	return wait
+}
+
+// parseRetryAfter understands both forms of the Retry-After header: delay in seconds and HTTP date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
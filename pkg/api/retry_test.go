@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	limited := func(d time.Duration) error {
+		return RateLimitExceededError{ApiError{StatusCode: 429, RetryAfter: d}}
+	}
+	tests := []struct {
+		name  string
+		retry int
+		err   error
+		want  time.Duration
+	}{
+		{"first retry", 1, ServerError{}, time.Second},
+		{"grows", 3, ServerError{}, 4 * time.Second},
+		{"capped", 5, ServerError{}, 5 * time.Second},
+		{"transport error", 2, errors.New("connection reset"), 2 * time.Second},
+		{"short retry after", 3, limited(2 * time.Second), 4 * time.Second},
+		{"retry after", 1, limited(3 * time.Second), 3 * time.Second},
+		{"retry after beyond max backoff", 1, limited(30 * time.Second), 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.backoff(tt.retry, tt.err); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SchedulerConfig configures a Scheduler, zero fields take the defaults
+type SchedulerConfig struct {
+	// Budget is the number of scheduled requests allowed per second
+	Budget float64
+	// Burst is the number of requests that may be sent at once before Budget applies
+	Burst int
+	// FastInterval is used when it is our turn or an opponent shot is imminent
+	FastInterval time.Duration
+	// NormalInterval is used while the opponent thinks
+	NormalInterval time.Duration
+	// SlowInterval is used while waiting in the lobby
+	SlowInterval time.Duration
+	// ImminentTimer is the opponent timer, in seconds, below which its shot is considered imminent
+	ImminentTimer int
+}
+
+// DefaultSchedulerConfig returns the configuration used for zero fields
+func DefaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		Budget:         4,
+		Burst:          4,
+		FastInterval:   250 * time.Millisecond,
+		NormalInterval: time.Second,
+		SlowInterval:   2 * time.Second,
+		ImminentTimer:  10,
+	}
+}
+
+// Scheduler owns the periodic calls of a game session. Duplicate in-flight
+// requests are coalesced into one, the game description is fetched until it
+// is known and then served from memory, and every call is paced by a shared
+// request budget. Watcher and KeepAlive poll through it.
+//
+// A shared request does not belong to the caller that started it: it keeps
+// the values of that caller's ctx but not its deadline or cancellation, so
+// that one caller giving up does not fail the others. Every caller stops
+// waiting once its own ctx is done, the request then runs on until the
+// client's timeout and retry policy end it.
+type Scheduler struct {
+	client  *Client
+	cfg     SchedulerConfig
+	limiter *limiter
+	flight  flight
+
+	mu   sync.Mutex
+	desc *GameDescription
+}
+
+// NewScheduler returns a Scheduler for the session of c
+func NewScheduler(c *Client, cfg SchedulerConfig) *Scheduler {
+	def := DefaultSchedulerConfig()
+	if cfg.Budget <= 0 {
+		cfg.Budget = def.Budget
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = def.Burst
+	}
+	if cfg.FastInterval <= 0 {
+		cfg.FastInterval = def.FastInterval
+	}
+	if cfg.NormalInterval <= 0 {
+		cfg.NormalInterval = def.NormalInterval
+	}
+	if cfg.SlowInterval <= 0 {
+		cfg.SlowInterval = def.SlowInterval
+	}
+	if cfg.ImminentTimer <= 0 {
+		cfg.ImminentTimer = def.ImminentTimer
+	}
+	return &Scheduler{
+		client:  c,
+		cfg:     cfg,
+		limiter: newLimiter(cfg.Budget, cfg.Burst),
+	}
+}
+
+// Status returns the current game status
+func (s *Scheduler) Status(ctx context.Context) (GameStatus, error) {
+	v, err := s.flight.do(ctx, GameURL, func(ctx context.Context) (interface{}, error) {
+		if err := s.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		return s.client.GetGameStatus(ctx)
+	})
+	status, _ := v.(GameStatus)
+	return status, err
+}
+
+// Board returns the player board
+func (s *Scheduler) Board(ctx context.Context) (*GameBoard, error) {
+	v, err := s.flight.do(ctx, BoardURL, func(ctx context.Context) (interface{}, error) {
+		if err := s.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		return s.client.GetGameBoard(ctx)
+	})
+	board, _ := v.(*GameBoard)
+	return board, err
+}
+
+// Description returns the game description. Once the opponent is known
+// the description cannot change anymore and is no longer fetched.
+func (s *Scheduler) Description(ctx context.Context) (GameDescription, error) {
+	s.mu.Lock()
+	if s.desc != nil {
+		d := *s.desc
+		s.mu.Unlock()
+		return d, nil
+	}
+	s.mu.Unlock()
+
+	v, err := s.flight.do(ctx, GameDescURL, func(ctx context.Context) (interface{}, error) {
+		if err := s.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		return s.client.GetGameDescription(ctx)
+	})
+	d, _ := v.(GameDescription)
+	if err == nil && d.Opponent != "" {
+		s.mu.Lock()
+		s.desc = &d
+		s.mu.Unlock()
+	}
+	return d, err
+}
+
+// Refresh keeps the session alive
+func (s *Scheduler) Refresh(ctx context.Context) error {
+	_, err := s.flight.do(ctx, RefreshURL, func(ctx context.Context) (interface{}, error) {
+		if err := s.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		return nil, s.client.RefreshGameSession(ctx)
+	})
+	return err
+}
+
+// Interval returns how long to wait before polling again, given the last status
+func (s *Scheduler) Interval(status GameStatus) time.Duration {
+	switch status.GameStatus {
+	case PhaseInProgress:
+		if status.ShouldFire || status.Timer <= s.cfg.ImminentTimer {
+			return s.cfg.FastInterval
+		}
+		return s.cfg.NormalInterval
+	default:
+		return s.cfg.SlowInterval
+	}
+}
+
+// flight coalesces concurrent calls sharing a key into a single call
+type flight struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// do runs fn for the first caller of key and lets later callers wait for its
+// result. fn gets ctx detached from its cancellation, see Scheduler.
+func (f *flight) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = map[string]*flightCall{}
+	}
+	c, ok := f.calls[key]
+	if !ok {
+		c = &flightCall{done: make(chan struct{})}
+		f.calls[key] = c
+		go func() {
+			c.val, c.err = fn(detached{ctx})
+
+			f.mu.Lock()
+			delete(f.calls, key)
+			f.mu.Unlock()
+			close(c.done)
+		}()
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// detached keeps the values of a context but is never done
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+// limiter is a token bucket refilled at rate tokens per second
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	return &limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or ctx is done
+func (l *limiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
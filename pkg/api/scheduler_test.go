@@ -0,0 +1,159 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"warships/pkg/api"
+)
+
+// drained returns a scheduler whose budget of one request every 200ms is spent
+func drained(t *testing.T, c *api.Client) *api.Scheduler {
+	t.Helper()
+	s := api.NewScheduler(c, api.SchedulerConfig{Budget: 5, Burst: 1})
+	if _, err := s.Status(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSchedulerCoalesces(t *testing.T) {
+	srv := newServer(t)
+	s := drained(t, startBotGame(t, srv, api.NoRetry()))
+	before := srv.Requests(api.GameURL)
+
+	// the first call waits for the budget, the others join it meanwhile
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, err := s.Status(context.Background()); err != nil || status.GameStatus != api.PhaseInProgress {
+				t.Errorf("got status %+v, %v", status, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := srv.Requests(api.GameURL) - before; n != 1 {
+		t.Errorf("%d request(s), want 1", n)
+	}
+}
+
+func TestSchedulerCanceledCaller(t *testing.T) {
+	srv := newServer(t)
+	s := drained(t, startBotGame(t, srv, api.NoRetry()))
+	before := srv.Requests(api.GameURL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	first := make(chan error)
+	go func() {
+		_, err := s.Status(ctx)
+		first <- err
+	}()
+	// let the first caller start the shared request
+	time.Sleep(5 * time.Millisecond)
+	status, err := s.Status(context.Background())
+	if err != nil || status.GameStatus != api.PhaseInProgress {
+		t.Errorf("got status %+v, %v after the first caller gave up", status, err)
+	}
+	if err := <-first; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("the first caller got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n := srv.Requests(api.GameURL) - before; n != 1 {
+		t.Errorf("%d request(s), want 1", n)
+	}
+}
+
+func TestSchedulerBudget(t *testing.T) {
+	srv := newServer(t)
+	s := api.NewScheduler(startBotGame(t, srv, api.NoRetry()), api.SchedulerConfig{Budget: 20, Burst: 2})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := s.Status(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("the burst took %v", elapsed)
+	}
+	// four more calls at 20 per second take 200ms
+	for i := 0; i < 4; i++ {
+		if _, err := s.Status(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("six calls took only %v", elapsed)
+	}
+}
+
+func TestSchedulerInterval(t *testing.T) {
+	s := api.NewScheduler(api.NewClient(), api.SchedulerConfig{
+		FastInterval:   time.Millisecond,
+		NormalInterval: time.Second,
+		SlowInterval:   time.Minute,
+		ImminentTimer:  10,
+	})
+	tests := []struct {
+		name   string
+		status api.GameStatus
+		want   time.Duration
+	}{
+		{"our turn", api.GameStatus{GameStatus: api.PhaseInProgress, ShouldFire: true, Timer: 60}, time.Millisecond},
+		{"opponent thinks", api.GameStatus{GameStatus: api.PhaseInProgress, Timer: 60}, time.Second},
+		{"opponent shot imminent", api.GameStatus{GameStatus: api.PhaseInProgress, Timer: 5}, time.Millisecond},
+		{"waiting", api.GameStatus{GameStatus: api.PhaseWaiting}, time.Minute},
+		{"ended", api.GameStatus{GameStatus: api.PhaseEnded}, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Interval(tt.status); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerDescription(t *testing.T) {
+	ctx := context.Background()
+	srv := newServer(t)
+	host := srv.Client(api.WithRetryPolicy(api.NoRetry()))
+	if _, err := host.StartGame(ctx, "host", "", "guest", layout, false); err != nil {
+		t.Fatal(err)
+	}
+	s := api.NewScheduler(host, quick)
+	description := func() api.GameDescription {
+		t.Helper()
+		d, err := s.Description(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	before := srv.Requests(api.GameDescURL)
+	description()
+	description()
+	if n := srv.Requests(api.GameDescURL) - before; n != 2 {
+		t.Errorf("%d request(s) without an opponent, want 2", n)
+	}
+
+	guest := srv.Client(api.WithRetryPolicy(api.NoRetry()))
+	if _, err := guest.StartGame(ctx, "guest", "", "host", layout, false); err != nil {
+		t.Fatal(err)
+	}
+	before = srv.Requests(api.GameDescURL)
+	for i := 0; i < 3; i++ {
+		if d := description(); d.Opponent != "guest" {
+			t.Errorf("got opponent %q", d.Opponent)
+		}
+	}
+	if n := srv.Requests(api.GameDescURL) - before; n != 1 {
+		t.Errorf("%d request(s) once the opponent is known, want 1", n)
+	}
+}
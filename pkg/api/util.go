@@ -1,24 +1,19 @@
 package api
 
-// setStatesFromCoords converts []string to [][]string
-func setStatesFromCoords(coords []string, s string) [10][10]string {
-	state := [10][10]string{}
-	for i := range state {
-		state[i] = [10]string{}
-	}
-	for _, coord := range coords {
-		x, y := mapToState(coord)
-		state[x][y] = s
-	}
-	return state
-}
+import (
+	"warships/pkg/rules"
+	"warships/pkg/state"
+)
 
-// mapToState converts string to int
-func mapToState(coord string) (int, int) {
-	if len(coord) > 2 {
-		return int(coord[0] - 65), 9
+// setStatesFromCoords converts []string to a state.Grid of the board of cfg
+func setStatesFromCoords(cfg rules.Config, coords []string, s state.Cell) (state.Grid, error) {
+	states := state.NewGrid(cfg.Width, cfg.Height)
+	for _, shot := range coords {
+		c, err := cfg.Parse(shot)
+		if err != nil {
+			return nil, err
+		}
+		states[c.X][c.Y] = s
 	}
-	x := int(coord[0] - 65)
-	y := int(coord[1] - 49)
-	return x, y
+	return states, nil
 }
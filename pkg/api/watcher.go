@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"time"
+	"warships/pkg/coord"
+)
+
+// Event is emitted by a Watcher whenever the game changes
+type Event interface {
+	// Status returns the snapshot in which the change was noticed
+	Status() GameStatus
+}
+
+type snapshot struct {
+	status GameStatus
+}
+
+func (s snapshot) Status() GameStatus {
+	return s.status
+}
+
+// PhaseChanged is emitted when the game status changes, e.g. from waiting to game_in_progress
+type PhaseChanged struct {
+	snapshot
+	Phase GamePhase
+}
+
+// OpponentJoined is emitted once the opponent is known
+type OpponentJoined struct {
+	snapshot
+	Opponent string
+}
+
+// TurnChanged is emitted when the player gains or loses the right to fire
+type TurnChanged struct {
+	snapshot
+	ShouldFire bool
+}
+
+// OpponentFired is emitted for every new opponent shot, Result is empty if the player board could not be loaded
+type OpponentFired struct {
+	snapshot
+	Coord  string
+	Result ShotResult
+}
+
+// TimerTick is emitted when the turn timer changes
+type TimerTick struct {
+	snapshot
+	Timer int
+}
+
+// GameEnded is emitted when the game is over
+type GameEnded struct {
+	snapshot
+	Outcome GameOutcome
+}
+
+// SessionLost is emitted when the server no longer knows the session
+type SessionLost struct {
+	snapshot
+	Err error
+}
+
+// Watcher polls the game status through a Scheduler, at the interval it
+// suggests for the current phase, diffs successive snapshots and emits typed events
+type Watcher struct {
+	scheduler *Scheduler
+	events    chan Event
+	// OnError is called with polling errors that do not end the session
+	OnError func(error)
+
+	prev  GameStatus
+	ships map[string]bool
+	hits  map[string]bool
+}
+
+// NewWatcher returns a Watcher polling through s
+func NewWatcher(s *Scheduler) *Watcher {
+	return &Watcher{
+		scheduler: s,
+		events:    make(chan Event, 64),
+		hits:      map[string]bool{},
+	}
+}
+
+// Events returns the event stream, it is closed when Run returns
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Run polls until ctx is done, the game ends or the session is lost
+func (w *Watcher) Run(ctx context.Context) {
+	defer close(w.events)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			status, err := w.scheduler.Status(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if IsUnauthorized(err) {
+					w.emit(ctx, SessionLost{snapshot{w.prev}, err})
+					return
+				}
+				w.report(err)
+				timer.Reset(w.scheduler.Interval(w.prev))
+				continue
+			}
+			if w.ships == nil && len(status.OppShots) > 0 {
+				w.loadShips(ctx)
+			}
+			for _, e := range w.diff(status) {
+				w.emit(ctx, e)
+			}
+			w.prev = status
+			if status.GameStatus == PhaseEnded {
+				return
+			}
+			timer.Reset(w.scheduler.Interval(status))
+		}
+	}
+}
+
+// loadShips fetches the player board, needed to tell hits from misses
+func (w *Watcher) loadShips(ctx context.Context) {
+	board, err := w.scheduler.Board(ctx)
+	if err != nil {
+		w.report(err)
+		return
+	}
+	w.ships = map[string]bool{}
+	for _, coord := range board.Board {
+		w.ships[coord] = true
+	}
+}
+
+// diff returns the events leading from the previous snapshot to status
+func (w *Watcher) diff(status GameStatus) []Event {
+	var events []Event
+	s := snapshot{status}
+	prev := w.prev
+
+	if status.GameStatus != prev.GameStatus {
+		events = append(events, PhaseChanged{s, status.GameStatus})
+	}
+	if status.Opponent != "" && status.Opponent != prev.Opponent {
+		events = append(events, OpponentJoined{s, status.Opponent})
+	}
+	if len(status.OppShots) > len(prev.OppShots) {
+		for _, coord := range status.OppShots[len(prev.OppShots):] {
+			events = append(events, OpponentFired{s, coord, w.resolve(coord)})
+		}
+	}
+	if status.GameStatus == PhaseInProgress &&
+		(status.ShouldFire != prev.ShouldFire || prev.GameStatus != PhaseInProgress) {
+		events = append(events, TurnChanged{s, status.ShouldFire})
+	}
+	if status.Timer != prev.Timer {
+		events = append(events, TimerTick{s, status.Timer})
+	}
+	if status.GameStatus == PhaseEnded && prev.GameStatus != PhaseEnded {
+		events = append(events, GameEnded{s, status.LastGameStatus})
+	}
+	return events
+}
+
+// resolve tells what an opponent shot at coord did to the player's fleet
+func (w *Watcher) resolve(coord string) ShotResult {
+	if w.ships == nil {
+		return ""
+	}
+	if !w.ships[coord] {
+		return ShotMiss
+	}
+	w.hits[coord] = true
+	for _, c := range w.shipAt(coord) {
+		if !w.hits[c] {
+			return ShotHit
+		}
+	}
+	return ShotSunk
+}
+
+// shipAt returns every cell of the ship occupying shot
+func (w *Watcher) shipAt(shot string) []string {
+	seen := map[string]bool{shot: true}
+	stack := []string{shot}
+	var ship []string
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		ship = append(ship, cur)
+		c, err := coord.Parse(cur)
+		if err != nil {
+			continue
+		}
+		for _, nc := range c.Neighbours(coord.Size, coord.Size) {
+			n := nc.String()
+			if w.ships[n] && !seen[n] {
+				seen[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return ship
+}
+
+func (w *Watcher) emit(ctx context.Context, e Event) {
+	select {
+	case w.events <- e:
+	case <-ctx.Done():
+	}
+}
+
+func (w *Watcher) report(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+	}
+}
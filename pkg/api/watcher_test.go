@@ -0,0 +1,134 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"warships/pkg/api"
+)
+
+// quick polls a fake server often enough for the tests to be quick
+var quick = api.SchedulerConfig{Budget: 1000, Burst: 10, FastInterval: 5 * time.Millisecond, NormalInterval: 5 * time.Millisecond, SlowInterval: 5 * time.Millisecond}
+
+func TestWatcher(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv := newServer(t)
+	host := srv.Client(api.WithRetryPolicy(api.NoRetry()))
+	guest := srv.Client(api.WithRetryPolicy(api.NoRetry()))
+	if _, err := host.StartGame(ctx, "host", "", "guest", layout, false); err != nil {
+		t.Fatal(err)
+	}
+
+	w := api.NewWatcher(api.NewScheduler(host, quick))
+	w.OnError = func(err error) { t.Errorf("polling: %v", err) }
+	go w.Run(ctx)
+	events := w.Events()
+
+	if e := await[api.PhaseChanged](t, events); e.Phase != api.PhaseWaiting {
+		t.Errorf("got phase %q, want %q", e.Phase, api.PhaseWaiting)
+	}
+	if _, err := guest.StartGame(ctx, "guest", "", "host", layout, false); err != nil {
+		t.Fatal(err)
+	}
+	if e := await[api.PhaseChanged](t, events); e.Phase != api.PhaseInProgress {
+		t.Errorf("got phase %q, want %q", e.Phase, api.PhaseInProgress)
+	}
+	if e := await[api.OpponentJoined](t, events); e.Opponent != "guest" {
+		t.Errorf("got opponent %q", e.Opponent)
+	}
+	// the host fires first
+	if e := await[api.TurnChanged](t, events); !e.ShouldFire {
+		t.Error("the host may not fire")
+	}
+
+	if _, err := host.Fire(ctx, api.FireData{Coord: "J10"}); err != nil {
+		t.Fatal(err)
+	}
+	if e := await[api.TurnChanged](t, events); e.ShouldFire {
+		t.Error("the host may fire after a miss")
+	}
+	want := []struct {
+		coord  string
+		result api.ShotResult
+	}{
+		{"A1", api.ShotHit},
+		{"E10", api.ShotSunk},
+		{"J1", api.ShotMiss},
+	}
+	for _, s := range want {
+		if _, err := guest.Fire(ctx, api.FireData{Coord: s.coord}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, s := range want {
+		if e := await[api.OpponentFired](t, events); e.Coord != s.coord || e.Result != s.result {
+			t.Errorf("got a shot at %s with %q, want %s with %q", e.Coord, e.Result, s.coord, s.result)
+		}
+	}
+	if e := await[api.TurnChanged](t, events); !e.ShouldFire {
+		t.Error("the host may not fire after the guest missed")
+	}
+
+	if err := guest.AbandonGame(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if e := await[api.PhaseChanged](t, events); e.Phase != api.PhaseEnded {
+		t.Errorf("got phase %q, want %q", e.Phase, api.PhaseEnded)
+	}
+	if e := await[api.GameEnded](t, events); e.Outcome != api.OutcomeWin {
+		t.Errorf("got outcome %q, want %q", e.Outcome, api.OutcomeWin)
+	}
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if _, tick := e.(api.TimerTick); !tick {
+				t.Errorf("got %T after the game ended", e)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("the events are not closed after the game ended")
+		}
+	}
+}
+
+func TestWatcherSessionLost(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv := newServer(t)
+	c := startBotGame(t, srv, api.NoRetry())
+	w := api.NewWatcher(api.NewScheduler(c, quick))
+	go w.Run(ctx)
+
+	await[api.TurnChanged](t, w.Events())
+	srv.Expire(c.Token)
+	if e := await[api.SessionLost](t, w.Events()); !api.IsUnauthorized(e.Err) {
+		t.Errorf("got %v, want the session expired", e.Err)
+	}
+	if _, ok := <-w.Events(); ok {
+		t.Error("the events are not closed after the session was lost")
+	}
+}
+
+// await returns the next event of type T, skipping the events of other types
+func await[T api.Event](t *testing.T, events <-chan api.Event) T {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				var zero T
+				t.Fatalf("the events are closed before %T", zero)
+			}
+			if e, ok := e.(T); ok {
+				return e
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("no %T", zero)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package coord implements board coordinates in the server notation, a
+// column letter followed by a row number, e.g. A1 or J10.
+package coord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Size is the width and height of the classic board
+const Size = 10
+
+// Coord is a board field, X is the column (A = 0) and Y the row (1 = 0)
+type Coord struct {
+	X, Y int
+}
+
+// ParseError is returned for coordinates that are malformed or off the board
+type ParseError struct {
+	Coord  string
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid coord %q: %s", e.Coord, e.Reason)
+}
+
+// Parse parses a coordinate of the classic board, case-insensitively
+func Parse(s string) (Coord, error) {
+	return ParseIn(s, Size, Size)
+}
+
+// ParseIn parses a coordinate of a w×h board, case-insensitively. Surrounding
+// space is ignored, zero-padded rows such as A01 are rejected.
+func ParseIn(s string, w, h int) (Coord, error) {
+	t := strings.ToUpper(strings.TrimSpace(s))
+	if len(t) < 2 {
+		return Coord{}, &ParseError{s, "too short"}
+	}
+	col := t[0]
+	if col < 'A' || col > 'Z' {
+		return Coord{}, &ParseError{s, "column must be a letter"}
+	}
+	row, err := strconv.Atoi(t[1:])
+	if err != nil || t[1] == '+' || t[1] == '-' {
+		return Coord{}, &ParseError{s, "row must be a number"}
+	}
+	if t[1] == '0' && len(t) > 2 {
+		return Coord{}, &ParseError{s, "row must not be zero-padded"}
+	}
+	c := Coord{X: int(col - 'A'), Y: row - 1}
+	if !c.In(w, h) {
+		return Coord{}, &ParseError{s, "off the board"}
+	}
+	return c, nil
+}
+
+// MustParse is like Parse but panics on error, it is meant for constants
+func MustParse(s string) Coord {
+	c, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// ParseAll parses every coordinate of coords, stopping at the first error
+func ParseAll(coords []string) ([]Coord, error) {
+	cs := make([]Coord, len(coords))
+	for i, s := range coords {
+		c, err := Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		cs[i] = c
+	}
+	return cs, nil
+}
+
+// Strings formats every coordinate of cs
+func Strings(cs []Coord) []string {
+	s := make([]string, len(cs))
+	for i, c := range cs {
+		s[i] = c.String()
+	}
+	return s
+}
+
+// FromIndex returns the coordinate of the board field [x][y]
+func FromIndex(x, y int) Coord {
+	return Coord{X: x, Y: y}
+}
+
+// Index returns the board indices of c
+func (c Coord) Index() (int, int) {
+	return c.X, c.Y
+}
+
+// String formats c in the server notation, e.g. A1
+func (c Coord) String() string {
+	return string(rune('A'+c.X)) + strconv.Itoa(c.Y+1)
+}
+
+// Valid reports whether c lies on the classic board
+func (c Coord) Valid() bool {
+	return c.In(Size, Size)
+}
+
+// In reports whether c lies on a w×h board
+func (c Coord) In(w, h int) bool {
+	return c.X >= 0 && c.X < w && c.Y >= 0 && c.Y < h
+}
+
+// Add returns c moved by dx columns and dy rows
+func (c Coord) Add(dx, dy int) Coord {
+	return Coord{X: c.X + dx, Y: c.Y + dy}
+}
+
+var (
+	orthogonal = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	diagonal   = [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+)
+
+// Neighbours returns the fields sharing an edge with c on a w×h board
+func (c Coord) Neighbours(w, h int) []Coord {
+	return c.moves(orthogonal, w, h)
+}
+
+// Diagonals returns the fields sharing only a corner with c on a w×h board
+func (c Coord) Diagonals(w, h int) []Coord {
+	return c.moves(diagonal, w, h)
+}
+
+// Around returns every field touching c on a w×h board, edges and corners
+func (c Coord) Around(w, h int) []Coord {
+	return append(c.Neighbours(w, h), c.Diagonals(w, h)...)
+}
+
+func (c Coord) moves(deltas [][2]int, w, h int) []Coord {
+	var cs []Coord
+	for _, d := range deltas {
+		if n := c.Add(d[0], d[1]); n.In(w, h) {
+			cs = append(cs, n)
+		}
+	}
+	return cs
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (c Coord) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (c *Coord) UnmarshalText(b []byte) error {
+	p, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*c = p
+	return nil
+}
@@ -0,0 +1,91 @@
+package coord
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseIn(t *testing.T) {
+	tests := []struct {
+		in      string
+		w, h    int
+		want    Coord
+		wantErr bool
+	}{
+		{in: "A1", w: 10, h: 10, want: Coord{0, 0}},
+		{in: "J10", w: 10, h: 10, want: Coord{9, 9}},
+		{in: "c7", w: 10, h: 10, want: Coord{2, 6}},
+		{in: "j10", w: 10, h: 10, want: Coord{9, 9}},
+		{in: " B2 ", w: 10, h: 10, want: Coord{1, 1}},
+		{in: "\tE5\n", w: 10, h: 10, want: Coord{4, 4}},
+		{in: "L12", w: 12, h: 12, want: Coord{11, 11}},
+		{in: "H8", w: 8, h: 8, want: Coord{7, 7}},
+
+		{in: "K1", w: 10, h: 10, wantErr: true},
+		{in: "A11", w: 10, h: 10, wantErr: true},
+		{in: "A0", w: 10, h: 10, wantErr: true},
+		{in: "I9", w: 8, h: 8, wantErr: true},
+		{in: "A01", w: 10, h: 10, wantErr: true},
+		{in: "a010", w: 10, h: 10, wantErr: true},
+		{in: "A00", w: 10, h: 10, wantErr: true},
+		{in: "A+1", w: 10, h: 10, wantErr: true},
+		{in: "A-1", w: 10, h: 10, wantErr: true},
+		{in: "A 1", w: 10, h: 10, wantErr: true},
+		{in: "1A", w: 10, h: 10, wantErr: true},
+		{in: "AA", w: 10, h: 10, wantErr: true},
+		{in: "A", w: 10, h: 10, wantErr: true},
+		{in: "", w: 10, h: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseIn(tt.in, tt.w, tt.h)
+			if tt.wantErr {
+				var pe *ParseError
+				if !errors.As(err, &pe) || pe.Coord != tt.in {
+					t.Errorf("got %v, %v, want a ParseError", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{in: "A1"},
+		{in: "j10"},
+		{in: " d4"},
+		{in: "K10", wantErr: true},
+		{in: "A11", wantErr: true},
+		{in: "B05", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			c, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v", err)
+			}
+			if err == nil && !c.Valid() {
+				t.Errorf("%v is off the board", c)
+			}
+		})
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for x := 0; x < 26; x++ {
+		for y := 0; y < 26; y++ {
+			c := Coord{x, y}
+			got, err := ParseIn(c.String(), 26, 26)
+			if err != nil || got != c {
+				t.Fatalf("%v: got %v, %v", c, got, err)
+			}
+		}
+	}
+}
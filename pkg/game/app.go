@@ -2,50 +2,57 @@ package game
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 	"warships/pkg/api"
+	"warships/pkg/layout"
+	"warships/pkg/record"
+	"warships/pkg/rules"
+	"warships/pkg/session"
 	"warships/pkg/state"
+	"warships/pkg/strategy"
 )
 
 type GameInterface interface {
-	FireShot(coord string) (api.FireResult, int, error)
-	StartGame(nick, desc, targetNick string, coords []string, botGame bool)
-	GetGameStatus() (api.GameStatus, error)
-	SetPlayerBoard(coords []string) ([10][10]string, error)
-	GetDescription() (api.GameDescription, error)
-	LoadPlayerBoard() (*api.GameBoard, error)
+	FireShot(ctx context.Context, coord string) (api.FireResult, int, error)
+	StartGame(ctx context.Context, nick, desc, targetNick string, coords []string, botGame bool) error
+	GetGameStatus(ctx context.Context) (api.GameStatus, error)
+	SetPlayerBoard(coords []string) (state.Grid, error)
+	GetDescription(ctx context.Context) (api.GameDescription, error)
+	LoadPlayerBoard(ctx context.Context) (*api.GameBoard, error)
 	UpdateGameState(nick string, desc string, opponent string, oppDesc string)
-	GetPlayerBoard() [10][10]string
+	GetPlayerBoard() state.Grid
 	MarkOpponentShots(shots []string)
 	GetGameState() (*state.GameState, error)
-	GetOpponentBoard() [10][10]string
+	GetOpponentBoard() state.Grid
 	MarkOpponent(shot string, result api.FireResult) int
 	UpdatePlayerInfo(name string, description string)
 	GetPlayerInfo() (string, string)
 	UpdatePlayersDesc(d api.GameDescription)
-	GetTopPlayerStats() (api.TopPlayerStats, error)
+	GetTopPlayerStats(ctx context.Context) (api.TopPlayerStats, error)
 	MarkPlayerShip(coords string)
 	GetPlayerCoords() []string
-	GetPlayerStats(name string) api.GameStats
-	GetPlayerLobby() []api.LobbyPlayer
+	GetPlayerStats(ctx context.Context, name string) (api.GameStats, error)
+	GetPlayerLobby(ctx context.Context) ([]api.LobbyPlayer, error)
 	ClearState()
 	UpdateLastGameStatus(status string)
 	LastGameStatus() string
-	AbortGame()
+	AbortGame(ctx context.Context) error
 }
 type GameStateInterface interface {
 	GetGameState() *state.GameState
 	UpdateGameState(nick, desc, opp, oppdesc string)
-	UpdatePlayerBoard(playerState [10][10]string) ([10][10]string, error)
-	UpdateOpponentBoard(opponentState [10][10]string) ([10][10]string, error)
-	GetPlayerBoard() [10][10]string
+	UpdatePlayerBoard(playerState state.Grid) (state.Grid, error)
+	UpdateOpponentBoard(opponentState state.Grid) (state.Grid, error)
+	GetPlayerBoard() state.Grid
 	MarkPlayerBoard(x, y int)
-	GetOpponentBoard() [10][10]string
-	MarkOpponentBoard(x int, y int, result string) int
+	GetOpponentBoard() state.Grid
+	MarkOpponentBoard(x int, y int, result state.Cell) int
 	IsHitAlready(x, y int) bool
-	IncreaseHits(str string)
+	IncreaseHits(hit bool)
 	GetTotalShots() int
 	GetTotalHits() int
 	UpdatePlayerInfo(name string, description string)
@@ -70,99 +77,190 @@ type App struct {
 	gameStateChannel   chan api.GameState
 	errChan            chan error
 	wg                 *sync.WaitGroup // Using a pointer to a WaitGroup
+	sessions           *session.Store
+	session            session.Session
+	sessionMu          sync.Mutex
+	scheduler          *api.Scheduler
+	keepAlive          *api.KeepAlive
+	layoutSeed         int64
+	uniformLayouts     *layout.Generator
+	strategicLayouts   *layout.Generator
+	layout             layout.Layout
+	library            *layout.Library
+	autopilot          *autopilot
+	recordMu           sync.Mutex
+	recordDir          string
+	recorder           *record.Recorder
 }
 
-func NewApp(gameStatusChannel chan api.GameStatus, playerShotsChannel chan string, gameStateChannel chan api.GameState) *App {
+func NewApp(gameStatusChannel chan api.GameStatus, playerShotsChannel chan string, gameStateChannel chan api.GameState, opts ...api.Option) *App {
+	// without a config directory games simply are not saved
+	sessions, _ := session.DefaultStore()
+	var library *layout.Library
+	var recordDir string
+	if dir, err := session.ConfigDir(); err == nil {
+		library = &layout.Library{Path: filepath.Join(dir, "layouts.json")}
+		recordDir = filepath.Join(dir, "games")
+	}
 	return &App{
 		gui:                NewGui(),
-		game:               api.NewGame(),
+		game:               api.NewGame(opts...),
 		playerShotsChannel: playerShotsChannel,
 		gameStatusChannel:  gameStatusChannel,
 		gameStateChannel:   gameStateChannel,
 		errChan:            make(chan error),  // Initializing errChan
 		wg:                 &sync.WaitGroup{}, // Initializing the WaitGroup
+		sessions:           sessions,
+		layoutSeed:         time.Now().UnixNano(),
+		library:            library,
+		autopilot:          newAutopilot(),
+		recordDir:          recordDir,
 	}
 }
 
-func (a *App) StartPlayerGame(ctx context.Context) {
+// SetConfig selects the variant to play, the server must play the same one
+func (a *App) SetConfig(cfg rules.Config) error {
+	if err := a.game.SetConfig(cfg); err != nil {
+		return err
+	}
+	a.uniformLayouts, a.strategicLayouts = nil, nil
+	a.layout = nil
+	return nil
+}
+
+// SetHintCount sets the number of fields recommended by the hint overlay
+func (a *App) SetHintCount(n int) {
+	a.gui.setHintCount(n)
+}
+
+// SetHintStrategy sets the strategy recommending fields in the hint overlay
+func (a *App) SetHintStrategy(s strategy.Strategy) {
+	a.gui.setHintStrategy(s)
+}
+
+// SetLayout makes every game start with l instead of asking for a layout
+func (a *App) SetLayout(l layout.Layout) error {
+	if err := l.Validate(a.game.Config()); err != nil {
+		return err
+	}
+	a.layout = l
+	return nil
+}
+
+// SetLayoutSeed seeds the generators of random layouts, making them reproducible
+func (a *App) SetLayoutSeed(seed int64) {
+	a.layoutSeed = seed
+	a.uniformLayouts, a.strategicLayouts = nil, nil
+}
+
+// chooseLayout lets the player place the ships or pick a generated layout,
+// it returns nil to let the server choose
+func (a *App) chooseLayout(ctx context.Context) []string {
+	cfg := a.game.Config()
+	if a.layout != nil {
+		fmt.Println("Playing the layout", a.layout.List())
+		return a.layout.Strings()
+	}
+
+	fmt.Println("would you like to place your ships? (y/n, l - pick one from the library)")
+	answer := readLine()
+	switch answer {
+	case "y":
+		a.PlaceShips(ctx)
+		return a.game.GetPlayerCoords()
+	case "l":
+		if l, ok := a.pickPreset(); ok {
+			return l.Strings()
+		}
+	}
+
+	if a.uniformLayouts == nil {
+		a.uniformLayouts = layout.NewGenerator(cfg, a.layoutSeed)
+		a.strategicLayouts = layout.NewStrategicGenerator(cfg, a.layoutSeed, layout.DefaultBias())
+	}
+	gen := a.uniformLayouts
 	for {
-		ctx, cancel := context.WithCancel(ctx)
-		var wg sync.WaitGroup
+		l, err := gen.Generate()
+		if err != nil {
+			fmt.Println("Could not generate a layout:", err)
+			return nil
+		}
+		fmt.Print(l.Draw(cfg))
+		fmt.Println("Play this layout? (y - yes, r - another random one, s - a strategic one, w - save it to a file, n - let the server choose)")
+		answer = readLine()
+		switch answer {
+		case "w":
+			a.saveLayout(l)
+			return l.Strings()
+		case "r":
+			gen = a.uniformLayouts
+		case "s":
+			gen = a.strategicLayouts
+		case "n":
+			return nil
+		default:
+			return l.Strings()
+		}
+	}
+}
+
+// saveLayout asks for a file name and writes l to it
+func (a *App) saveLayout(l layout.Layout) {
+	fmt.Println("Enter file name: ")
+	path := readLine()
+	if path == "" {
+		return
+	}
+	if err := layout.Save(path, l, a.game.Config()); err != nil {
+		fmt.Println("Could not save the layout:", err)
+	}
+}
+
+// StartLayoutGame starts a single game with the layout read from a file
+func (a *App) StartLayoutGame(ctx context.Context) {
+	fmt.Println("Enter layout file: ")
+	path := readLine()
+	l, err := layout.Load(path, a.game.Config())
+	if err != nil {
+		fmt.Println("Could not load the layout:", err)
+		return
+	}
+	fmt.Print(l.Draw(a.game.Config()))
 
+	fmt.Println("Play against the bot? (y/n)")
+	answer := readLine()
+	bot := answer != "n"
+	var targetNick string
+	if !bot {
+		fmt.Println("Enter target nick: ")
+		targetNick = readLine()
+	}
+
+	nick, desc := a.game.GetPlayerInfo()
+	if err := a.startGame(ctx, nick, desc, targetNick, l.Strings(), bot); err != nil {
+		fmt.Println("Could not start the game:", err)
+		return
+	}
+	a.playGame(ctx)
+}
+
+func (a *App) StartPlayerGame(ctx context.Context) {
+	for {
 		nick, desc := a.game.GetPlayerInfo()
-		fmt.Println("would you like to place your ships? (y/n)")
-		var answer string
-		fmt.Scanln(&answer)
-		if answer == "y" {
-			a.PlaceShips(ctx)
-		}
-		coords := a.game.GetPlayerCoords()
+		coords := a.chooseLayout(ctx)
 
 		var targetNick string
 		fmt.Println("Enter target nick: ")
-		fmt.Scanln(&targetNick)
+		targetNick = readLine()
 
-		a.game.StartGame(nick, desc, targetNick, coords, false)
-		board, err := a.game.LoadPlayerBoard()
-		if err != nil {
-			a.errChan <- err // Send error to errChan
-		}
-		_, err = a.game.SetPlayerBoard(board.Board)
-
-		_, err = a.game.GetDescription()
-
-		wg.Add(8)
-		go func() {
-			defer wg.Done()
-			a.updateGameStatus(ctx)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.gui.handleGameState(ctx, a.gameStateChannel)
-		}()
-		go func() {
-			defer wg.Done()
-			a.updateGameState(ctx, cancel)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.gui.displayBoard(ctx)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.gui.handleGameStatus(ctx, a.gameStatusChannel)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.handleError(ctx, cancel)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.readPlayerShots(ctx)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.gui.listenPlayerShots(ctx, a.playerShotsChannel)
-		}()
-		a.gui.gui.Start(ctx, nil)
-		fmt.Println("Abort?")
-		var c string
-		fmt.Scanln(&c)
-		if c == "y" {
-			a.game.AbortGame()
+		if err := a.startGame(ctx, nick, desc, targetNick, coords, false); err != nil {
+			fmt.Println("Could not start the game:", err)
+			return
 		}
-
-		wg.Wait()
+		a.playGame(ctx)
 
 		fmt.Println("Would you like to play again? (y/n)")
-		var choice string
-		fmt.Scanln(&choice)
+		choice := readLine()
 		if choice == "n" {
 			break
 		}
@@ -172,78 +270,19 @@ func (a *App) StartPlayerGame(ctx context.Context) {
 
 func (a *App) StartBotGame(ctx context.Context) {
 	for {
-		ctx, cancel := context.WithCancel(ctx)
-		var wg sync.WaitGroup
-		wg.Add(8)
 		nick, desc := a.game.GetPlayerInfo()
 
-		fmt.Println("would you like to place your ships?")
-		var answer string
-		fmt.Scanln(&answer)
-		if answer == "y" {
-			a.PlaceShips(ctx)
-		}
-		coords := a.game.GetPlayerCoords()
-
-		a.game.StartGame(nick, desc, "", coords, true)
-		board, err := a.game.LoadPlayerBoard()
+		coords := a.chooseLayout(ctx)
 
-		if err != nil {
-			a.errChan <- err // Send error to errChan
-		}
-		_, err = a.game.SetPlayerBoard(board.Board)
-		go func() {
-			defer wg.Done()
-			a.updateGameStatus(ctx)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.gui.handleGameState(ctx, a.gameStateChannel)
-		}()
-		go func() {
-			defer wg.Done()
-			a.updateGameState(ctx, cancel)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.gui.displayBoard(ctx)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.gui.handleGameStatus(ctx, a.gameStatusChannel)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.handleError(ctx, cancel)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.readPlayerShots(ctx)
-		}()
-
-		go func() {
-			defer wg.Done()
-			a.gui.listenPlayerShots(ctx, a.playerShotsChannel)
-		}()
-		a.gui.gui.Start(ctx, nil)
-		fmt.Println("Abort?")
-		var c string
-		fmt.Scanln(&c)
-		if c == "y" {
-			a.game.AbortGame()
+		if err := a.startGame(ctx, nick, desc, "", coords, true); err != nil {
+			fmt.Println("Could not start the game:", err)
+			return
 		}
-
-		wg.Wait()
+		a.playGame(ctx)
 
 		a.game.LastGameStatus()
 		fmt.Println("Would you like to play again? (y/n)")
-		var choice string
-		fmt.Scanln(&choice)
+		choice := readLine()
 		if choice == "n" {
 			break
 		}
@@ -252,35 +291,245 @@ func (a *App) StartBotGame(ctx context.Context) {
 
 }
 
-// updates game status from the server
+// startGame starts a game on the server, loads the player board and saves the session
+func (a *App) startGame(ctx context.Context, nick, desc, targetNick string, coords []string, botGame bool) error {
+	if err := a.game.StartGame(ctx, nick, desc, targetNick, coords, botGame); err != nil {
+		return err
+	}
+	board, err := a.game.LoadPlayerBoard(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := a.game.SetPlayerBoard(board.Board); err != nil {
+		return err
+	}
+
+	started := time.Now()
+	a.session = session.Session{
+		Token:     a.game.Token(),
+		Server:    a.game.ServerURL(),
+		Bot:       botGame,
+		StartedAt: started,
+		Record:    a.startRecording("", nick, desc, botGame, started),
+	}
+	a.saveSession()
+	return nil
+}
+
+// playGame runs the game until the GUI is closed or the game ends
+func (a *App) playGame(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	var wg sync.WaitGroup
+
+	a.gui.setConfig(a.game.Config())
+	a.scheduler = a.game.Scheduler(api.SchedulerConfig{})
+	a.keepAlive = api.NewKeepAlive(a.scheduler, api.DefaultKeepAliveInterval)
+	a.keepAlive.OnError = func(err error) {
+		a.reportError(ctx, fmt.Errorf("keeping the session alive: %w", err))
+	}
+
+	wg.Add(11)
+	go func() {
+		defer wg.Done()
+		a.keepAlive.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		a.updateGameStatus(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		a.gui.handleGameState(ctx, a.gameStateChannel)
+	}()
+	go func() {
+		defer wg.Done()
+		a.updateGameState(ctx, cancel)
+	}()
+
+	go func() {
+		defer wg.Done()
+		a.gui.displayBoard(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		a.gui.handleGameStatus(ctx, a.gameStatusChannel)
+	}()
+
+	go func() {
+		defer wg.Done()
+		a.handleError(ctx, cancel)
+	}()
+
+	go func() {
+		defer wg.Done()
+		a.readPlayerShots(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		a.gui.listenPlayerShots(ctx, a.playerShotsChannel)
+	}()
+
+	go func() {
+		defer wg.Done()
+		a.handleKeys(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		a.runAutopilot(ctx)
+	}()
+	a.gui.gui.Start(ctx, nil)
+	cancel()
+	wg.Wait()
+	// a game left running is recorded further if it is resumed
+	a.stopRecording()
+
+	a.sessionMu.Lock()
+	running := a.session.Token != ""
+	a.sessionMu.Unlock()
+	if !running {
+		return
+	}
+	fmt.Println("Abort?")
+	c := readLine()
+	if c == "y" {
+		if err := a.game.AbortGame(parent); err != nil {
+			fmt.Println("Could not abort the game:", err)
+		}
+		a.clearSession()
+		return
+	}
+	fmt.Println("The game is saved, you can resume it after restarting the app")
+}
+
+// OfferResume asks the player to resume a game saved by a previous run of the app
+func (a *App) OfferResume(ctx context.Context) {
+	if a.sessions == nil {
+		return
+	}
+	sess, err := a.sessions.Load()
+	if err != nil {
+		if !errors.Is(err, session.ErrNoSession) {
+			fmt.Println("Could not read the saved game:", err)
+		}
+		return
+	}
+	if sess.Server != a.game.ServerURL() {
+		return
+	}
+
+	opponent := sess.Opponent
+	if opponent == "" {
+		opponent = "an unknown opponent"
+	}
+	fmt.Printf("Resume the game against %s started at %s? (y/n)\n", opponent, sess.StartedAt.Format(time.Stamp))
+	answer := readLine()
+	if answer != "y" {
+		a.sessions.Clear()
+		return
+	}
+
+	status, err := a.game.Resume(ctx, sess.Token, sess.State, sess.Shots)
+	if err != nil {
+		fmt.Println("Could not resume the game:", err)
+		a.sessions.Clear()
+		fmt.Println("Press any key to continue...")
+		readLine()
+		return
+	}
+	a.session = *sess
+	a.session.Opponent = status.Opponent
+	nick, desc := a.game.GetPlayerInfo()
+	a.session.Record = a.startRecording(sess.Record, nick, desc, sess.Bot, sess.StartedAt)
+	a.saveSession()
+	a.playGame(ctx)
+}
+
+// saveSession persists the current game, failures are logged but do not interrupt the game
+func (a *App) saveSession() {
+	if a.sessions == nil {
+		return
+	}
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	if a.session.Token == "" {
+		return
+	}
+	a.session.Shots = a.game.Shots()
+	a.session.State = a.game.Snapshot()
+	if err := a.sessions.Save(a.session); err != nil {
+		a.gui.gui.Log("Could not save the game: %v", err)
+	}
+}
+
+// setSessionOpponent records the opponent once it is known
+func (a *App) setSessionOpponent(opponent string) {
+	a.sessionMu.Lock()
+	changed := opponent != "" && a.session.Opponent != opponent
+	a.session.Opponent = opponent
+	a.sessionMu.Unlock()
+	if changed {
+		a.saveSession()
+	}
+}
+
+// clearSession forgets the saved game once it cannot be resumed anymore
+func (a *App) clearSession() {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	a.session = session.Session{}
+	if a.sessions != nil {
+		a.sessions.Clear()
+	}
+}
+
+// updates game status from the server, reacting to the events of a watcher
 func (a *App) updateGameState(ctx context.Context, cancel context.CancelFunc) {
-	ticker := time.NewTicker(500 * time.Millisecond)
-	defer ticker.Stop()
-loop:
-	for {
-		select {
-		case <-ctx.Done():
-			fmt.Println("Done updating Game state ")
-			break loop
-		case <-ticker.C:
-			state, err := a.game.GetGameStatus()
-			a.game.UpdateLastGameStatus(state.LastGameStatus)
+	w := api.NewWatcher(a.scheduler)
+	w.OnError = func(err error) {
+		a.reportError(ctx, err)
+	}
+	go w.Run(ctx)
+
+	for e := range w.Events() {
+		state := e.Status()
+		a.game.UpdateLastGameStatus(string(state.LastGameStatus))
+		a.keepAlive.Observe(state)
+		a.observeTurn(state)
+		a.recordEvent(ctx, e)
+
+		switch e := e.(type) {
+		case api.OpponentJoined:
+			a.setSessionOpponent(e.Opponent)
+			d, err := a.scheduler.Description(ctx)
 			if err != nil {
-				a.errChan <- err // Send error to errChan
-				continue
-			}
-			if state.GameStatus == "ended" {
-				a.game.ClearState()
-				cancel()
-				return
+				a.reportError(ctx, err)
+				break
 			}
-			if state.GameStatus == "game_in_progress" {
-				d, _ := a.game.GetDescription()
-				a.game.UpdatePlayersDesc(d)
-			}
-			oppShots := state.OppShots
-			a.game.MarkOpponentShots(oppShots)
-			a.gameStatusChannel <- state
+			a.game.UpdatePlayersDesc(d)
+			a.recordPlayers(ctx, d)
+		case api.OpponentFired:
+			a.game.MarkOpponentShots([]string{e.Coord})
+		case api.GameEnded:
+			a.stopRecording()
+			a.game.ClearState()
+			a.clearSession()
+			cancel()
+		case api.SessionLost:
+			// the session is gone, there is nothing left to watch
+			a.reportError(ctx, e.Err)
+			a.stopRecording()
+			a.clearSession()
+			cancel()
+		}
+
+		select {
+		case a.gameStatusChannel <- state:
+		case <-ctx.Done():
 		}
 	}
 }
@@ -297,10 +546,11 @@ func (a *App) updateGameStatus(ctx context.Context) {
 		case <-ticker.C:
 			state, err := a.game.GetGameState()
 			if err != nil {
-				a.errChan <- err
+				a.reportError(ctx, err)
 				continue
 			}
-			a.gameStateChannel <- api.GameState{
+			gameState := api.GameState{
+				Config:       state.Config(),
 				PlayerBoard:  state.GetPlayerBoard(),
 				OppBoard:     state.GetOpponentBoard(),
 				TotalHits:    state.GetTotalHits(),
@@ -309,10 +559,22 @@ func (a *App) updateGameStatus(ctx context.Context) {
 				OppDesc:      state.GetOppDesc(),
 				OppShipsSunk: state.GetOppShipsSunk(),
 			}
+			select {
+			case a.gameStateChannel <- gameState:
+			case <-ctx.Done():
+			}
 		}
 	}
 }
 
+// reportError hands err to handleError unless the game is already over
+func (a *App) reportError(ctx context.Context, err error) {
+	select {
+	case a.errChan <- err:
+	case <-ctx.Done():
+	}
+}
+
 // Modified handleError to accept a cancel function
 func (a *App) handleError(ctx context.Context, cancel context.CancelFunc) {
 loop:
@@ -324,6 +586,26 @@ loop:
 			break loop
 		case err := <-a.errChan:
 			a.gui.gui.Log("Error: %v", err)
+			a.gui.showError(err)
+		}
+	}
+}
+
+// handleKeys reacts to the keys pressed during the game
+func (a *App) handleKeys(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case key := <-a.gui.keys.Keys():
+			switch key {
+			case 'h':
+				a.gui.toggleHints()
+			case 'm':
+				a.gui.toggleHeatmap()
+			case 'p':
+				a.toggleAutopilot()
+			}
 		}
 	}
 }
@@ -336,34 +618,49 @@ loop:
 			fmt.Println("Done reading shots OK")
 			break loop
 		case shot := <-a.playerShotsChannel:
-			_, _, err := a.game.FireShot(shot)
-			if err != nil {
-				a.errChan <- err
-			}
+			a.fireShot(ctx, shot)
 		}
 	}
 }
 
+// fireShot fires at shot for the player or the autopilot and saves the game
+func (a *App) fireShot(ctx context.Context, shot string) {
+	result, _, err := a.game.FireShot(ctx, shot)
+	if err != nil {
+		a.reportError(ctx, err)
+		return
+	}
+	if c, err := a.game.Config().Parse(shot); err == nil {
+		a.record(ctx, func(r *record.Recorder) error { return r.Shot(c.String(), result) })
+	}
+	a.saveSession()
+}
+
 func (a *App) EnterPlayerInfo(ctx context.Context) {
 	fmt.Println("Enter your nick: ")
-	var name string
-	fmt.Scanln(&name)
+	name := readLine()
 	fmt.Println("Enter your description: ")
-	var description string
-	fmt.Scanln(&description)
+	description := readLine()
 
 	a.game.UpdatePlayerInfo(name, description)
 }
 
 func (a *App) GetPlayerStats(ctx context.Context) {
 	fmt.Println("Enter player nick: ")
-	var name string
-	fmt.Scanln(&name)
-	stats := a.game.GetPlayerStats(name)
+	name := readLine()
+	stats, err := a.game.GetPlayerStats(ctx, name)
+	if err != nil {
+		fmt.Println("An error occurred:", err)
+		return
+	}
 	fmt.Println(stats)
 }
 
-func (a *App) PrintLobby() {
-	lobby := a.game.GetPlayerLobby()
+func (a *App) PrintLobby(ctx context.Context) {
+	lobby, err := a.game.GetPlayerLobby(ctx)
+	if err != nil {
+		fmt.Println("An error occurred:", err)
+		return
+	}
 	fmt.Println(lobby)
 }
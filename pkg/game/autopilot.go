@@ -0,0 +1,134 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/strategy"
+)
+
+// defaultThinkTime is how long the autopilot waits before every shot
+const defaultThinkTime = 500 * time.Millisecond
+
+// autopilot fires on the player's behalf while it is switched on
+type autopilot struct {
+	mu       sync.Mutex
+	on       bool
+	think    time.Duration
+	strategy strategy.Strategy
+	// turn is set while the latest status lets the player fire
+	turn bool
+	wake chan struct{}
+}
+
+func newAutopilot() *autopilot {
+	return &autopilot{
+		think:    defaultThinkTime,
+		strategy: strategy.NewDensity(strategy.Options{}),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// ready reports whether the autopilot should fire now
+func (p *autopilot) ready() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.on && p.turn
+}
+
+// signal wakes the autopilot if it should fire
+func (p *autopilot) signal() {
+	if !p.ready() {
+		return
+	}
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SetAutopilot switches the autopilot on or off
+func (a *App) SetAutopilot(on bool) {
+	a.autopilot.mu.Lock()
+	a.autopilot.on = on
+	a.autopilot.mu.Unlock()
+	a.gui.setAutopilot(on)
+	a.autopilot.signal()
+}
+
+// SetThinkTime sets how long the autopilot waits before every shot
+func (a *App) SetThinkTime(d time.Duration) {
+	a.autopilot.mu.Lock()
+	defer a.autopilot.mu.Unlock()
+	a.autopilot.think = d
+}
+
+// SetStrategy replaces the way the autopilot chooses its shots
+func (a *App) SetStrategy(s strategy.Strategy) {
+	a.autopilot.mu.Lock()
+	defer a.autopilot.mu.Unlock()
+	a.autopilot.strategy = s
+}
+
+// toggleAutopilot lets the player take over from the autopilot or hand back control
+func (a *App) toggleAutopilot() {
+	a.autopilot.mu.Lock()
+	on := !a.autopilot.on
+	a.autopilot.mu.Unlock()
+	a.SetAutopilot(on)
+}
+
+// observeTurn tells the autopilot whose turn it is
+func (a *App) observeTurn(status api.GameStatus) {
+	a.autopilot.mu.Lock()
+	a.autopilot.turn = status.ShouldFire && status.GameStatus == api.PhaseInProgress
+	a.autopilot.mu.Unlock()
+	a.autopilot.signal()
+}
+
+// runAutopilot fires a shot every time the autopilot is woken on the player's turn
+func (a *App) runAutopilot(ctx context.Context) {
+	p := a.autopilot
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+		}
+
+		p.mu.Lock()
+		think, s := p.think, p.strategy
+		p.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(think):
+		}
+		// the player may have taken over meanwhile
+		if !p.ready() {
+			continue
+		}
+
+		gs, err := a.game.GetGameState()
+		if err != nil {
+			a.reportError(ctx, err)
+			continue
+		}
+		target, err := strategy.FromState(s, gs)
+		if err != nil {
+			a.reportError(ctx, err)
+			continue
+		}
+		// wait for the next status before firing again, the pending one
+		// may predate this shot
+		p.mu.Lock()
+		p.turn = false
+		p.mu.Unlock()
+		select {
+		case <-p.wake:
+		default:
+		}
+		a.fireShot(ctx, target.String())
+	}
+}
@@ -0,0 +1,229 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"warships/pkg/coord"
+	"warships/pkg/layout"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+)
+
+var (
+	errFleetPlaced = errors.New("all ships are placed, press enter to play")
+	errNoShip      = errors.New("there is no ship on this field")
+	errNoUndo      = errors.New("nothing to undo")
+	errNoRedo      = errors.New("nothing to redo")
+)
+
+// editor holds the ships placed so far and the ship to place next. Every
+// change of the ships can be undone.
+type editor struct {
+	cfg   rules.Config
+	gen   *layout.Generator
+	ships []rules.Ship
+	// length of the ship placed by the next click, 0 when the fleet is complete
+	length   int
+	down     bool
+	deleting bool
+	undos    [][]rules.Ship
+	redos    [][]rules.Ship
+}
+
+func newEditor(cfg rules.Config, gen *layout.Generator) *editor {
+	e := &editor{cfg: cfg, gen: gen}
+	e.selectNext()
+	return e
+}
+
+// cells returns the fields of all placed ships
+func (e *editor) cells() []coord.Coord {
+	var cells []coord.Coord
+	for _, s := range e.ships {
+		cells = append(cells, s...)
+	}
+	return cells
+}
+
+// remaining returns the ships still to be placed
+func (e *editor) remaining() rules.Fleet {
+	left := e.cfg.Fleet.Clone()
+	for _, s := range e.ships {
+		left[len(s)]--
+	}
+	return left
+}
+
+// selectNext keeps the selected length while such ships are left and
+// otherwise selects the longest ship left
+func (e *editor) selectNext() {
+	left := e.remaining()
+	if left[e.length] > 0 {
+		return
+	}
+	e.length = 0
+	for _, n := range left.Sizes() {
+		if left[n] > 0 {
+			e.length = n
+			return
+		}
+	}
+}
+
+// choose selects the length of the next ship
+func (e *editor) choose(n int) error {
+	if e.remaining()[n] <= 0 {
+		return fmt.Errorf("no ship of length %d left to place", n)
+	}
+	e.length = n
+	return nil
+}
+
+func (e *editor) rotate() {
+	e.down = !e.down
+}
+
+// click places the selected ship with its first field at c, picks up the
+// ship at c to move it or deletes it in delete mode
+func (e *editor) click(c coord.Coord) error {
+	i := e.shipAt(c)
+	if e.deleting {
+		e.deleting = false
+		if i < 0 {
+			return errNoShip
+		}
+		e.record()
+		e.ships = append(e.ships[:i:i], e.ships[i+1:]...)
+		e.selectNext()
+		return nil
+	}
+	if i >= 0 {
+		ship := e.ships[i]
+		e.record()
+		e.ships = append(e.ships[:i:i], e.ships[i+1:]...)
+		e.length = len(ship)
+		if len(ship) > 1 {
+			e.down = ship[0].X == ship[1].X
+		}
+		return nil
+	}
+	if e.length == 0 {
+		return errFleetPlaced
+	}
+	ship := make(rules.Ship, e.length)
+	for j := range ship {
+		if e.down {
+			ship[j] = c.Add(0, j)
+		} else {
+			ship[j] = c.Add(j, 0)
+		}
+	}
+	if err := rules.CheckShip(e.cfg, ship, e.cells()); err != nil {
+		return err
+	}
+	e.record()
+	// never append in place, the undo history shares the old slice
+	n := len(e.ships)
+	e.ships = append(e.ships[:n:n], ship)
+	e.selectNext()
+	return nil
+}
+
+// shipAt returns the index of the ship covering c or -1
+func (e *editor) shipAt(c coord.Coord) int {
+	for i, s := range e.ships {
+		if s.Contains(c) {
+			return i
+		}
+	}
+	return -1
+}
+
+// complete places the remaining ships at random
+func (e *editor) complete() error {
+	if e.length == 0 {
+		return errFleetPlaced
+	}
+	l, err := e.gen.Complete(e.cells())
+	if err != nil {
+		return err
+	}
+	set := map[coord.Coord]bool{}
+	for _, c := range l {
+		set[c] = true
+	}
+	e.record()
+	e.ships = rules.Ships(set)
+	e.selectNext()
+	return nil
+}
+
+// clear removes all ships
+func (e *editor) clear() {
+	if len(e.ships) == 0 {
+		return
+	}
+	e.record()
+	e.ships = nil
+	e.selectNext()
+}
+
+// record saves the ships before a change
+func (e *editor) record() {
+	e.undos = append(e.undos, e.ships)
+	e.redos = nil
+}
+
+func (e *editor) undo() error {
+	if len(e.undos) == 0 {
+		return errNoUndo
+	}
+	e.redos = append(e.redos, e.ships)
+	e.ships = e.undos[len(e.undos)-1]
+	e.undos = e.undos[:len(e.undos)-1]
+	e.selectNext()
+	return nil
+}
+
+func (e *editor) redo() error {
+	if len(e.redos) == 0 {
+		return errNoRedo
+	}
+	e.undos = append(e.undos, e.ships)
+	e.ships = e.redos[len(e.redos)-1]
+	e.redos = e.redos[:len(e.redos)-1]
+	e.selectNext()
+	return nil
+}
+
+// validate checks the complete fleet
+func (e *editor) validate() ([]coord.Coord, error) {
+	cells := e.cells()
+	if _, err := rules.Validate(e.cfg, cells); err != nil {
+		return nil, err
+	}
+	return cells, nil
+}
+
+// grid returns the board with the placed ships
+func (e *editor) grid() state.Grid {
+	g := state.NewGrid(e.cfg.Width, e.cfg.Height)
+	for _, c := range e.cells() {
+		g[c.X][c.Y] = state.Ship
+	}
+	return g
+}
+
+// status describes what the next click does
+func (e *editor) status() string {
+	switch {
+	case e.deleting:
+		return "Click a ship to delete it"
+	case e.length == 0:
+		return "All ships placed, press enter to play"
+	case e.down:
+		return fmt.Sprintf("Place a ship of length %d, downwards", e.length)
+	default:
+		return fmt.Sprintf("Place a ship of length %d, to the right", e.length)
+	}
+}
@@ -1,8 +1,10 @@
 package game
 
+import "warships/pkg/state"
+
 type GameEvent struct {
-	PlayerStates   [10][10]string
-	OpponentStates [10][10]string
+	PlayerStates   state.Grid
+	OpponentStates state.Grid
 	PlayerName     string
 	PlayerDesc     string
 	OpponentName   string
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	gui "github.com/grupawp/warships-gui/v2"
-	"strconv"
 	"sync"
 	"warships/pkg/api"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
 	"warships/pkg/state"
+	"warships/pkg/strategy"
+	"warships/pkg/targeting"
 )
 
 type Gui struct {
@@ -21,10 +24,22 @@ type Gui struct {
 	turn           *gui.Text
 	timer          *gui.Text
 	waiting        *gui.Text
-	numberOf1Ships *gui.Text
-	numberOf2Ships *gui.Text
-	numberOf3Ships *gui.Text
-	numberOf4Ships *gui.Text
+	errorText      *gui.Text
+	shipsLeft      map[int]*gui.Text
+	boardSize      *gui.Text
+	config         rules.Config
+	keys           *keyListener
+	hints          *overlay
+	hintText       *gui.Text
+	autoText       *gui.Text
+	hintCount      int
+	showHints      bool
+	showHeatmap    bool
+	hintStrategy   strategy.Strategy
+	heat           *targeting.Heatmap
+	top            []targeting.Rated
+	heatBoard      state.Grid
+	lastState      api.GameState
 	gameStateChan  <-chan *state.GameState
 	timerChan      <-chan int
 	gameStatusChan chan api.GameStatus
@@ -40,24 +55,65 @@ func (g *Gui) SetPlayerBoard(states [10][10]gui.State) {
 // NewGui - creates new gui
 func NewGui() *Gui {
 	return &Gui{
-		gui:            gui.NewGUI(false),
-		playerNick:     gui.NewText(playerNickX, playerNickY, "Player", nil),
-		playerDesc:     gui.NewText(playerDescX, playerDescY, "Your board", nil),
-		opponentNick:   gui.NewText(opponentNickX, opponentNickY, "Opponent", nil),
-		opponentDesc:   gui.NewText(opponentDescX, opponentDescY, "Opponent board", nil),
-		playerBoard:    gui.NewBoard(playerBoardX, playerBoardY, nil),
-		opponentBoard:  gui.NewBoard(opponentBoardX, opponentBoardY, nil),
-		waiting:        gui.NewText(10, 10, "Waiting for opponent...", nil),
-		turn:           gui.NewText(1, 3, "", nil),
-		timer:          gui.NewText(timerX, timerY, "", nil),
-		mu:             sync.Mutex{},
-		numberOf1Ships: gui.NewText(100, 9, "4 ships of length 1", nil),
-		numberOf2Ships: gui.NewText(100, 10, "3 ships of length 2", nil),
-		numberOf3Ships: gui.NewText(100, 11, "2 ships of length 3", nil),
-		numberOf4Ships: gui.NewText(100, 12, "1 ship of lengthj 4", nil),
+		gui:           gui.NewGUI(false),
+		playerNick:    gui.NewText(playerNickX, playerNickY, "Player", nil),
+		playerDesc:    gui.NewText(playerDescX, playerDescY, "Your board", nil),
+		opponentNick:  gui.NewText(opponentNickX, opponentNickY, "Opponent", nil),
+		opponentDesc:  gui.NewText(opponentDescX, opponentDescY, "Opponent board", nil),
+		playerBoard:   gui.NewBoard(playerBoardX, playerBoardY, nil),
+		opponentBoard: gui.NewBoard(opponentBoardX, opponentBoardY, nil),
+		waiting:       gui.NewText(10, 10, "Waiting for opponent...", nil),
+		turn:          gui.NewText(1, 3, "", nil),
+		timer:         gui.NewText(timerX, timerY, "", nil),
+		errorText:     gui.NewText(errorX, errorY, "", nil),
+		boardSize:     gui.NewText(legendX, legendY+4, "", nil),
+		keys:          newKeyListener(),
+		hints:         newOverlay(opponentBoardX, opponentBoardY),
+		hintText:      gui.NewText(hintX, hintY, "", nil),
+		autoText:      gui.NewText(hintX, hintY+1, autopilotText(false), nil),
+		hintCount:     defaultHints,
+		hintStrategy:  strategy.NewDensity(strategy.Options{}),
+		mu:            sync.Mutex{},
 	}
 }
 
+// setConfig prepares the fleet counters and the legend for the variant cfg
+func (g *Gui) setConfig(cfg rules.Config) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, t := range g.shipsLeft {
+		g.gui.Remove(t)
+	}
+	g.config = cfg
+	g.shipsLeft = map[int]*gui.Text{}
+	for i, l := range cfg.Fleet.Sizes() {
+		g.shipsLeft[l] = gui.NewText(legendX, legendY+6+i, shipsLeftText(cfg.Fleet[l], l), nil)
+	}
+	g.boardSize.SetText("")
+	if cfg.Width != coord.Size || cfg.Height != coord.Size {
+		last := coord.FromIndex(cfg.Width-1, cfg.Height-1)
+		g.boardSize.SetText(fmt.Sprintf("%dx%d board, M beyond %v is off the board", cfg.Width, cfg.Height, last))
+	}
+}
+
+// setAutopilot shows whether the autopilot is firing
+func (g *Gui) setAutopilot(on bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.autoText.SetText(autopilotText(on))
+}
+
+func autopilotText(on bool) string {
+	if on {
+		return "Autopilot on, p - take over"
+	}
+	return "p - autopilot"
+}
+
+func shipsLeftText(n, length int) string {
+	return fmt.Sprintf("%d ship(s) of length %d", n, length)
+}
+
 func (g *Gui) sendPlayerShots(ctx context.Context, shotsChannel chan string) {
 	coord := g.playerBoard.Listen(ctx)
 	shotsChannel <- coord
@@ -67,6 +123,21 @@ func (g *Gui) displayBoard(ctx context.Context) {
 	g.gui.Draw(g.playerBoard)
 	g.gui.Draw(g.opponentBoard)
 
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	// the overlay must be drawn after the board it covers
+	g.gui.Remove(g.hints)
+	g.gui.Remove(g.keys)
+	g.gui.Draw(g.hints)
+	g.gui.Draw(g.keys)
+	g.heat = nil
+	g.lastState = api.GameState{}
+	g.updateHints()
+	g.gui.Remove(g.hintText)
+	g.gui.Draw(g.hintText)
+	g.gui.Remove(g.autoText)
+	g.gui.Draw(g.autoText)
+
 }
 
 func (g *Gui) handleGameStatus(ctx context.Context, events chan api.GameStatus) {
@@ -81,14 +152,13 @@ loop:
 			g.updateTimer(status)
 			g.updatePlayers(status)
 			g.gui.Draw(g.waiting)
-			g.gui.Draw(g.numberOf1Ships)
-			g.gui.Draw(g.numberOf2Ships)
-			g.gui.Draw(g.numberOf3Ships)
-			g.gui.Draw(g.numberOf4Ships)
-			if status.GameStatus == "ended" {
+			for _, t := range g.shipsLeft {
+				g.gui.Draw(t)
+			}
+			if status.GameStatus == api.PhaseEnded {
 				g.gui.Draw(gui.NewText(5, 10, "Game ended. Press ctrl + c to go back to the menu", nil))
 			}
-			if status.GameStatus == "game_in_progress" {
+			if status.GameStatus == api.PhaseInProgress {
 				g.waiting.SetText("")
 				g.gui.Draw(g.waiting)
 			}
@@ -134,17 +204,26 @@ loop:
 			g.gui.Draw(gui.NewText(opponentDescX, opponentDescY, gameState.OppDesc, nil))
 			g.gui.Draw(gui.NewText(1, 2, fmt.Sprintf("Accuracy: %s %%",
 				getAccuracy(gameState.TotalHits, gameState.TotalShots)), nil))
+			for l, t := range g.shipsLeft {
+				t.SetText(shipsLeftText(gameState.OppShipsSunk[l], l))
+			}
+			g.lastState = gameState
+			g.updateHints()
 			g.mu.Unlock()
 
-			g.numberOf1Ships.SetText(strconv.Itoa(gameState.OppShipsSunk[1]) + " ships of length 1")
-			g.numberOf2Ships.SetText(strconv.Itoa(gameState.OppShipsSunk[2]) + " ships of length 2")
-			g.numberOf3Ships.SetText(strconv.Itoa(gameState.OppShipsSunk[3]) + " ships of length 3")
-			g.numberOf4Ships.SetText(strconv.Itoa(gameState.OppShipsSunk[4]) + " ships of length 4")
 			g.drawLegend()
 		}
 	}
 }
 
+// showError displays the last error below the boards
+func (g *Gui) showError(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.errorText.SetText(fmt.Sprintf("Error: %v", err))
+	g.gui.Draw(g.errorText)
+}
+
 func getAccuracy(hits, shots int) string {
 	if shots == 0 {
 		return "0.00"
@@ -165,24 +244,38 @@ loop:
 			break loop
 		default:
 			shot := g.opponentBoard.Listen(ctx)
-			if shot != "" && !contains(s, shot) {
+			if _, err := g.config.Parse(shot); err != nil {
+				continue
+			}
+			if !contains(s, shot) {
 				s = append(s, shot)
-				shots <- shot
+				select {
+				case shots <- shot:
+				case <-ctx.Done():
+				}
 			}
 		}
 	}
 }
 func (g *Gui) drawLegend() {
-	g.gui.Draw(gui.NewText(100, 4, "H - Hit", nil))
-	g.gui.Draw(gui.NewText(100, 5, "M - Miss", nil))
-	g.gui.Draw(gui.NewText(100, 6, "S - Ship", nil))
-	g.gui.Draw(gui.NewText(100, 7, "~ - Empty", nil))
+	g.gui.Draw(gui.NewText(legendX, legendY, "H - Hit", nil))
+	g.gui.Draw(gui.NewText(legendX, legendY+1, "M - Miss", nil))
+	g.gui.Draw(gui.NewText(legendX, legendY+2, "S - Ship", nil))
+	g.gui.Draw(gui.NewText(legendX, legendY+3, "~ - Empty", nil))
+	g.gui.Draw(g.boardSize)
 }
 
-func mapStatesToGuiMarks(sts [10][10]string) [10][10]gui.State {
+// mapStatesToGuiMarks maps sts onto the fixed 10x10 GUI board, fields beyond
+// a smaller board are drawn as missed so that they are never clicked
+func mapStatesToGuiMarks(sts state.Grid) [10][10]gui.State {
 	var mapped [10][10]gui.State
-	for i, row := range sts {
-		for j, s := range row {
+	for i := range mapped {
+		for j := range mapped[i] {
+			if !sts.In(i, j) {
+				mapped[i][j] = gui.Miss
+				continue
+			}
+			s := sts[i][j]
 			if s == state.Sunk {
 				s = state.Hit
 			}
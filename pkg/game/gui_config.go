@@ -15,4 +15,10 @@ const (
 	opponentDescY  = 28
 	timerX         = 1
 	timerY         = 1
+	errorX         = 1
+	errorY         = 30
+	legendX        = 100
+	legendY        = 4
+	hintX          = legendX
+	hintY          = legendY + 13
 )
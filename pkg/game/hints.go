@@ -0,0 +1,167 @@
+package game
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	tl "github.com/grupawp/termloop"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+	"warships/pkg/strategy"
+	"warships/pkg/targeting"
+)
+
+// defaultHints is the number of fields recommended by the hint overlay
+const defaultHints = 3
+
+var (
+	hintFg = tl.RgbTo256Color(0, 0, 0)
+	hintBg = tl.RgbTo256Color(255, 220, 0)
+	heatFg = tl.RgbTo256Color(255, 255, 255)
+	// heat colors from unlikely to likely fields
+	heatBg = []tl.Attr{
+		tl.RgbTo256Color(30, 40, 110),
+		tl.RgbTo256Color(50, 90, 170),
+		tl.RgbTo256Color(190, 150, 40),
+		tl.RgbTo256Color(230, 100, 30),
+		tl.RgbTo256Color(220, 30, 30),
+	}
+)
+
+// overlay draws over the fields of a board, it is placed at the same
+// position as the board and drawn after it
+type overlay struct {
+	id    uuid.UUID
+	cells [coord.Size][coord.Size]*tl.Text
+}
+
+func newOverlay(x, y int) *overlay {
+	o := &overlay{id: uuid.New()}
+	for i := range o.cells {
+		for j := range o.cells[i] {
+			// fields are 3 wide with a gap, the first row and column hold the ruler
+			o.cells[i][j] = tl.NewText(x+(i+1)*4, y+(j+1)*2, "", hintFg, hintBg)
+		}
+	}
+	return o
+}
+
+func (o *overlay) ID() uuid.UUID {
+	return o.id
+}
+
+func (o *overlay) Drawables() []tl.Drawable {
+	var d []tl.Drawable
+	for i := range o.cells {
+		for j := range o.cells[i] {
+			d = append(d, o.cells[i][j])
+		}
+	}
+	return d
+}
+
+// clear uncovers the whole board
+func (o *overlay) clear() {
+	for i := range o.cells {
+		for j := range o.cells[i] {
+			o.cells[i][j].SetText("")
+		}
+	}
+}
+
+func (o *overlay) set(c coord.Coord, text string, fg, bg tl.Attr) {
+	if c.X < 0 || c.X >= coord.Size || c.Y < 0 || c.Y >= coord.Size {
+		return
+	}
+	o.cells[c.X][c.Y].SetText(text)
+	o.cells[c.X][c.Y].SetColor(fg, bg)
+}
+
+// toggleHints shows or hides the recommended fields
+func (g *Gui) toggleHints() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.showHints = !g.showHints
+	g.updateHints()
+}
+
+// toggleHeatmap shows or hides the chances of all fields
+func (g *Gui) toggleHeatmap() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.showHeatmap = !g.showHeatmap
+	g.updateHints()
+}
+
+// setHintStrategy sets the strategy recommending the fields
+func (g *Gui) setHintStrategy(s strategy.Strategy) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.hintStrategy = s
+	g.heat = nil
+	g.updateHints()
+}
+
+// setHintCount sets the number of recommended fields
+func (g *Gui) setHintCount(n int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.hintCount = n
+}
+
+// updateHints redraws the overlay of the opponent board, the hints are only
+// computed again after the board has changed. g.mu must be held.
+func (g *Gui) updateHints() {
+	g.hints.clear()
+	g.hintText.SetText("h - hints, m - heatmap")
+	if !g.showHints && !g.showHeatmap || g.lastState.OppBoard == nil {
+		return
+	}
+	if g.heat == nil || !g.heatBoard.Equal(g.lastState.OppBoard) {
+		board := g.lastState.OppBoard
+		g.heat, g.top = recommend(g.hintStrategy, g.lastState.Config, board, rules.Fleet(g.lastState.OppShipsSunk))
+		g.heatBoard = board.Clone()
+	}
+
+	if g.showHeatmap {
+		g.hints.paintHeatmap(g.heat)
+	}
+	if g.showHints {
+		top := g.hints.paintHints(g.top, g.hintCount)
+		if len(top) > 0 {
+			g.hintText.SetText(fmt.Sprintf("Hint: %v (%.0f%%)", top[0].Coord, top[0].P*100))
+		}
+	}
+}
+
+// recommend computes the chances of the fields of board and ranks them with s
+func recommend(s strategy.Strategy, cfg rules.Config, board state.Grid, remaining rules.Fleet) (*targeting.Heatmap, []targeting.Rated) {
+	heat := targeting.Compute(cfg, board, remaining)
+	var top []targeting.Rated
+	if r, ok := s.(strategy.Ranker); ok {
+		top = r.Rank(cfg, board, remaining)
+	} else if c, err := s.Next(cfg, board, remaining); err == nil {
+		top = []targeting.Rated{{Coord: c, P: heat.P(c)}}
+	}
+	return &heat, top
+}
+
+// paintHeatmap colors every field by the chance of a ship
+func (o *overlay) paintHeatmap(heat *targeting.Heatmap) {
+	top := heat.Max()
+	for _, r := range heat.Ranked() {
+		level := int(r.P / top * float64(len(heatBg)-1))
+		o.set(r.Coord, " ~ ", heatFg, heatBg[level])
+	}
+}
+
+// paintHints numbers the first n fields of ranked and returns them
+func (o *overlay) paintHints(ranked []targeting.Rated, n int) []targeting.Rated {
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	for i, r := range ranked {
+		o.set(r.Coord, fmt.Sprintf(" %d ", i+1), hintFg, hintBg)
+	}
+	return ranked
+}
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"github.com/google/uuid"
+	tl "github.com/grupawp/termloop"
+)
+
+// keys without a character are sent by keyListener as runes no key types
+const (
+	keyEnter     = '\r'
+	keyBackspace = '\b'
+	keyLeft      = '\u2190'
+	keyRight     = '\u2192'
+	keyHome      = '\u21e4'
+	keyEnd       = '\u21e5'
+)
+
+// keyListener is an invisible drawable passing key presses to a channel,
+// the GUI library only reports clicks on boards
+type keyListener struct {
+	id   uuid.UUID
+	keys chan rune
+}
+
+func newKeyListener() *keyListener {
+	return &keyListener{id: uuid.New(), keys: make(chan rune, 16)}
+}
+
+// Keys returns the pressed characters, keys pressed while nobody reads are dropped
+func (k *keyListener) Keys() <-chan rune {
+	return k.keys
+}
+
+func (k *keyListener) ID() uuid.UUID {
+	return k.id
+}
+
+func (k *keyListener) Drawables() []tl.Drawable {
+	return []tl.Drawable{k}
+}
+
+func (k *keyListener) Tick(ev tl.Event) {
+	if ev.Type != tl.EventKey {
+		return
+	}
+	ch := ev.Ch
+	switch ev.Key {
+	case tl.KeyEnter:
+		ch = keyEnter
+	case tl.KeySpace:
+		ch = ' '
+	case tl.KeyBackspace, tl.KeyBackspace2:
+		ch = keyBackspace
+	case tl.KeyArrowLeft:
+		ch = keyLeft
+	case tl.KeyArrowRight:
+		ch = keyRight
+	case tl.KeyHome:
+		ch = keyHome
+	case tl.KeyEnd:
+		ch = keyEnd
+	}
+	if ch == 0 {
+		return
+	}
+	select {
+	case k.keys <- ch:
+	default:
+	}
+}
+
+func (k *keyListener) Draw(*tl.Screen) {}
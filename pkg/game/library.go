@@ -0,0 +1,221 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"warships/pkg/coord"
+	"warships/pkg/layout"
+)
+
+// presetFilter selects the presets of the current variant and player
+func (a *App) presetFilter(tag string) layout.Filter {
+	nick, _ := a.game.GetPlayerInfo()
+	return layout.Filter{Variant: a.game.Config().Name, Profile: nick, Tag: tag}
+}
+
+// printPresets lists presets with their number, tags and owner
+func printPresets(presets []layout.Preset) {
+	if len(presets) == 0 {
+		fmt.Println("The library has no layouts for this variant")
+		return
+	}
+	for i, p := range presets {
+		line := fmt.Sprintf("%2d. %s", i+1, p.Name)
+		if len(p.Tags) > 0 {
+			line += " [" + strings.Join(p.Tags, ", ") + "]"
+		}
+		if p.Profile != "" {
+			line += " (" + p.Profile + " only)"
+		}
+		fmt.Println(line)
+	}
+}
+
+// pickPreset asks for a preset by number or name, or for a random one by tag
+func (a *App) pickPreset() (layout.Layout, bool) {
+	if a.library == nil {
+		fmt.Println("The layout library is not available")
+		return nil, false
+	}
+	presets, err := a.library.List(a.presetFilter(""))
+	if err != nil {
+		fmt.Println("Could not read the library:", err)
+		return nil, false
+	}
+	printPresets(presets)
+	if len(presets) == 0 {
+		return nil, false
+	}
+	fmt.Println("Enter a number or name, or tag:<tag> for a random layout with that tag")
+	answer := readLine()
+
+	var p layout.Preset
+	if tag, ok := strings.CutPrefix(answer, "tag:"); ok {
+		p, err = a.library.Random(a.presetFilter(tag), rand.New(rand.NewSource(a.layoutSeed)))
+		a.layoutSeed++
+	} else {
+		p, err = findPreset(presets, answer)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return nil, false
+	}
+	fmt.Println("Playing", p.Name)
+	fmt.Print(p.Layout.Draw(a.game.Config()))
+	return p.Layout, true
+}
+
+// findPreset returns the preset with the given number in the listing or name
+func findPreset(presets []layout.Preset, answer string) (layout.Preset, error) {
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(presets) {
+		return presets[n-1], nil
+	}
+	for _, p := range presets {
+		if strings.EqualFold(p.Name, answer) {
+			return p, nil
+		}
+	}
+	return layout.Preset{}, fmt.Errorf("%s: %w", answer, layout.ErrNoPreset)
+}
+
+// ManageLibrary lists, previews, creates and deletes the saved layouts
+func (a *App) ManageLibrary(ctx context.Context) {
+	if a.library == nil {
+		fmt.Println("The layout library is not available")
+		return
+	}
+	for {
+		fmt.Println("Layout library")
+		fmt.Println("1. List layouts")
+		fmt.Println("2. Preview a layout")
+		fmt.Println("3. Create a layout")
+		fmt.Println("4. Delete a layout")
+		fmt.Println("5. List tags")
+		fmt.Println("0. Back")
+
+		choice, err := strconv.Atoi(readLine())
+		if err != nil {
+			fmt.Println("An error occurred:", err)
+			continue
+		}
+		switch choice {
+		case 0:
+			return
+		case 1:
+			presets, err := a.library.List(a.presetFilter(""))
+			if err != nil {
+				fmt.Println("Could not read the library:", err)
+				continue
+			}
+			printPresets(presets)
+		case 2:
+			a.previewPreset()
+		case 3:
+			a.createPreset(ctx)
+		case 4:
+			fmt.Println("Enter name: ")
+			name := readLine()
+			if err := a.library.Delete(name); err != nil {
+				fmt.Println("Could not delete the layout:", err)
+			}
+		case 5:
+			tags, err := a.library.Tags()
+			if err != nil {
+				fmt.Println("Could not read the library:", err)
+				continue
+			}
+			fmt.Println(strings.Join(tags, ", "))
+		default:
+			fmt.Println("Invalid option. Please enter a number between 0 and 5.")
+		}
+	}
+}
+
+func (a *App) previewPreset() {
+	fmt.Println("Enter name: ")
+	name := readLine()
+	p, err := a.library.Get(name)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(p.Name, "-", p.Variant, strings.Join(p.Tags, ", "))
+	fmt.Println(p.Layout.List())
+	if p.Variant == a.game.Config().Name {
+		fmt.Print(p.Layout.Draw(a.game.Config()))
+	}
+}
+
+// createPreset builds a layout by hand, from a style or from a file and saves it
+func (a *App) createPreset(ctx context.Context) {
+	cfg := a.game.Config()
+	styles := layout.Styles()
+	names := make([]string, 0, len(styles))
+	for name := range styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Enter name: ")
+	name := readLine()
+	fmt.Println("Enter tags separated by commas: ")
+	tags := readLine()
+	fmt.Printf("Where from? (p - place by hand, r - random, f - file, or a style: %s)\n", strings.Join(names, ", "))
+	source := readLine()
+
+	var l layout.Layout
+	var err error
+	switch source {
+	case "p":
+		// place on the side, the player board of the game stays as it is
+		a.editLayout(ctx, func(cells []coord.Coord) error {
+			l = cells
+			return nil
+		})
+		if l == nil {
+			fmt.Println("No layout placed")
+			return
+		}
+	case "r":
+		l, err = layout.NewGenerator(cfg, a.layoutSeed).Generate()
+		a.layoutSeed++
+	case "f":
+		fmt.Println("Enter layout file: ")
+		path := readLine()
+		l, err = layout.Load(path, cfg)
+	default:
+		bias, ok := styles[source]
+		if !ok {
+			fmt.Println("Unknown source", source)
+			return
+		}
+		l, err = layout.NewStrategicGenerator(cfg, a.layoutSeed, bias).Generate()
+		a.layoutSeed++
+	}
+	if err != nil {
+		fmt.Println("Could not build the layout:", err)
+		return
+	}
+	fmt.Print(l.Draw(cfg))
+
+	p := layout.Preset{Name: name, Layout: l}
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			p.Tags = append(p.Tags, t)
+		}
+	}
+	fmt.Println("Keep it for your profile only? (y/n)")
+	answer := readLine()
+	if answer == "y" {
+		p.Profile, _ = a.game.GetPlayerInfo()
+	}
+	if err := a.library.Create(p, cfg); err != nil {
+		fmt.Println("Could not save the layout:", err)
+		return
+	}
+	fmt.Println("Saved", p.Name)
+}
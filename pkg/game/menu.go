@@ -1,13 +1,26 @@
 package game
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
+	"strings"
 )
 
+// stdin is shared by every prompt, a reader per prompt would lose what it buffered
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads a line of input without the surrounding space, so answers
+// of several words such as a description or tags are read whole
+func readLine() string {
+	line, _ := stdin.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
 func clear() {
 	var c *exec.Cmd
 	if runtime.GOOS == "windows" {
@@ -20,6 +33,7 @@ func clear() {
 }
 
 func (a *App) Menu(ctx context.Context) {
+	a.OfferResume(ctx)
 	for {
 		clear()
 
@@ -31,10 +45,12 @@ func (a *App) Menu(ctx context.Context) {
 		fmt.Println("5. Show player stats")
 		fmt.Println("6. Show Player Lobby")
 		fmt.Println("7. Exit")
+		fmt.Println("8. Start game from layout file")
+		fmt.Println("9. Manage layout library")
+		fmt.Println("10. Replay a recorded game")
 		fmt.Println("0. Return to menu")
 
-		var choice int
-		_, err := fmt.Scanln(&choice)
+		choice, err := strconv.Atoi(readLine())
 		if err != nil {
 			fmt.Println("An error occurred:", err)
 			continue
@@ -48,7 +64,7 @@ func (a *App) Menu(ctx context.Context) {
 		case 3:
 			a.EnterPlayerInfo(ctx)
 		case 4:
-			stats, err := a.game.GetTopPlayerStats()
+			stats, err := a.game.GetTopPlayerStats(ctx)
 			if err != nil {
 				fmt.Println("An error occurred:", err)
 				continue
@@ -60,12 +76,18 @@ func (a *App) Menu(ctx context.Context) {
 			fmt.Println("Bye!")
 			os.Exit(0)
 		case 6:
-			a.PrintLobby()
+			a.PrintLobby(ctx)
+		case 8:
+			a.StartLayoutGame(ctx)
+		case 9:
+			a.ManageLibrary(ctx)
+		case 10:
+			a.ReplayGame(ctx)
 		default:
-			fmt.Println("Invalid option. Please enter a number between 0 and 6.")
+			fmt.Println("Invalid option. Please enter a number between 0 and 10.")
 		}
 		fmt.Println("Press ane key to continue...")
-		fmt.Scanln()
+		readLine()
 
 	}
 }
@@ -4,65 +4,134 @@ import (
 	"context"
 	"fmt"
 	gui "github.com/grupawp/warships-gui/v2"
-	"sync"
+	"warships/pkg/coord"
+	"warships/pkg/layout"
 )
 
+var placementHelp = []string{
+	"click  - place the ship, or pick up a placed one to move it",
+	"r      - rotate",
+	"1-9    - choose the length of the next ship",
+	"x      - delete the next clicked ship",
+	"u / y  - undo / redo",
+	"c      - remove all ships",
+	"a      - place the remaining ships at random",
+	"enter  - play this layout",
+	"ctrl+c - cancel",
+}
+
+// PlaceShips runs the placement editor and sets the player board once the
+// player confirms a complete fleet
 func (a *App) PlaceShips(ctx context.Context) {
-	var mutex sync.Mutex
+	a.editLayout(ctx, func(cells []coord.Coord) error {
+		_, err := a.game.SetPlayerBoard(coord.Strings(cells))
+		return err
+	})
+}
 
-	ships := map[int]int{
-		4: 1,
-		3: 2,
-		2: 3,
-		1: 4,
-	}
-	currStates := [10][10]gui.State{}
-	newStates := [10][10]gui.State{}
-	var fullCoords []string
+// editLayout runs the placement editor until accept takes a complete fleet
+// confirmed by the player, or the player cancels. An error of accept is shown
+// and editing goes on.
+func (a *App) editLayout(parent context.Context, accept func(cells []coord.Coord) error) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	cfg := a.game.Config()
+	ed := newEditor(cfg, layout.NewGenerator(cfg, a.layoutSeed))
+	a.layoutSeed++
 
-	board := gui.NewBoard(0, 0, nil)
-	hint := gui.NewText(50, 0, "Place some ship(s)", nil)
-	invalid := gui.NewText(50, 1, "", nil)
 	placeGui := gui.NewGUI(false)
+	board := gui.NewBoard(0, 0, nil)
+	status := gui.NewText(50, 0, "", nil)
+	message := gui.NewText(50, 1, "", nil)
+	fleet := map[int]*gui.Text{}
+	sizes := cfg.Fleet.Sizes()
+	for i, n := range sizes {
+		fleet[n] = gui.NewText(50, 3+i, "", nil)
+		placeGui.Draw(fleet[n])
+	}
+	for i, line := range placementHelp {
+		placeGui.Draw(gui.NewText(50, 4+len(sizes)+i, line, nil))
+	}
+	keys := newKeyListener()
 	placeGui.Draw(board)
-	placeGui.Draw(hint)
+	placeGui.Draw(status)
+	placeGui.Draw(message)
+	placeGui.Draw(keys)
+
+	redraw := func(err error) {
+		board.SetStates(mapStatesToGuiMarks(ed.grid()))
+		status.SetText(ed.status())
+		if err != nil {
+			message.SetText(err.Error())
+		} else {
+			message.SetText("")
+		}
+		left := ed.remaining()
+		for _, n := range sizes {
+			marker := "  "
+			if n == ed.length {
+				marker = "> "
+			}
+			fleet[n].SetText(fmt.Sprintf("%slength %d: %d of %d left", marker, n, left[n], cfg.Fleet[n]))
+		}
+	}
+
+	clicks := make(chan string)
 	go func() {
-		for k, v := range ships {
-			hint.SetText(fmt.Sprintf("Place %v ship(s) of length %v", v, k))
-			placeGui.Draw(hint)
-			for i := 0; i < v; i++ {
-				var coords []string
-				for j := 0; j < k; j++ {
-					coord := board.Listen(ctx)
-					coords = append(coords, coord)
-					x, y := mapToState(coord)
+		for {
+			field := board.Listen(ctx)
+			select {
+			case clicks <- field:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
-					mutex.Lock()
-					newStates[x][y] = gui.Ship
-					mutex.Unlock()
-					board.SetStates(newStates)
+	go func() {
+		redraw(nil)
+		for {
+			var err error
+			select {
+			case <-ctx.Done():
+				return
+			case field := <-clicks:
+				c, perr := cfg.Parse(field)
+				if perr != nil {
+					continue
 				}
-				mutex.Lock()
-				valid := isValidPlacement(coords) && touchesAnotherShip(coords, currStates) == false
-				mutex.Unlock()
-				if valid {
-					invalid.SetText("")
-					currStates = newStates
-					fullCoords = append(fullCoords, coords...)
-				} else {
-					invalid.SetText("Invalid placement, try again")
-					placeGui.Draw(invalid)
-					for _, coord := range coords {
-						x, y := mapToState(coord)
-						newStates[x][y] = gui.Empty
+				err = ed.click(c)
+			case key := <-keys.Keys():
+				switch key {
+				case 'r':
+					ed.rotate()
+				case 'x':
+					ed.deleting = !ed.deleting
+				case 'u':
+					err = ed.undo()
+				case 'y':
+					err = ed.redo()
+				case 'c':
+					ed.clear()
+				case 'a':
+					err = ed.complete()
+				case keyEnter:
+					var cells []coord.Coord
+					if cells, err = ed.validate(); err == nil {
+						if err = accept(cells); err == nil {
+							cancel()
+							return
+						}
+					}
+				default:
+					if key >= '1' && key <= '9' {
+						err = ed.choose(int(key - '0'))
 					}
-					board.SetStates(newStates)
-					i--
 				}
 			}
+			redraw(err)
 		}
-		hint.SetText("Done placing ships. Press ctrl+c save and exit")
-		a.game.SetPlayerBoard(fullCoords)
 	}()
 	placeGui.Start(ctx, nil)
 }
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/record"
+)
+
+// SetRecordDir sets the directory games are recorded to, empty turns recording off
+func (a *App) SetRecordDir(dir string) {
+	a.recordMu.Lock()
+	defer a.recordMu.Unlock()
+	a.recordDir = dir
+}
+
+// startRecording opens the recording of the game of nick just started, or
+// reopens path when a game is resumed. It returns the path of the recording,
+// which is empty if the game is not recorded.
+func (a *App) startRecording(path, nick, desc string, botGame bool, started time.Time) string {
+	a.recordMu.Lock()
+	defer a.recordMu.Unlock()
+	if a.recorder != nil {
+		a.recorder.Close()
+		a.recorder = nil
+	}
+	if a.recordDir == "" {
+		return ""
+	}
+	if path == "" {
+		path = filepath.Join(a.recordDir, record.FileName(nick, started))
+	}
+	r, err := record.Create(path, record.Header{
+		Nick:    nick,
+		Desc:    desc,
+		Bot:     botGame,
+		Server:  a.game.ServerURL(),
+		Rules:   a.game.Config(),
+		Layout:  a.game.GetPlayerCoords(),
+		Started: started,
+	})
+	if err != nil {
+		a.gui.gui.Log("Could not record the game: %v", err)
+		return ""
+	}
+	a.recorder = r
+	return path
+}
+
+// record runs fn with the recorder of the running game, if there is one
+func (a *App) record(ctx context.Context, fn func(r *record.Recorder) error) {
+	a.recordMu.Lock()
+	r := a.recorder
+	a.recordMu.Unlock()
+	if r == nil {
+		return
+	}
+	if err := fn(r); err != nil {
+		a.stopRecording()
+		a.reportError(ctx, err)
+	}
+}
+
+// recordEvent writes the event of a watcher to the recording
+func (a *App) recordEvent(ctx context.Context, e api.Event) {
+	switch e := e.(type) {
+	case api.PhaseChanged:
+		a.record(ctx, func(r *record.Recorder) error { return r.Phase(e.Phase) })
+	case api.OpponentFired:
+		a.record(ctx, func(r *record.Recorder) error { return r.OpponentShot(e.Coord, e.Result) })
+	case api.TurnChanged:
+		a.record(ctx, func(r *record.Recorder) error { return r.Turn(e.ShouldFire) })
+	case api.TimerTick:
+		a.record(ctx, func(r *record.Recorder) error { return r.Timer(e.Timer) })
+	case api.GameEnded:
+		a.record(ctx, func(r *record.Recorder) error { return r.End(e.Outcome) })
+	}
+}
+
+// recordPlayers completes the header once the opponent and the descriptions are known
+func (a *App) recordPlayers(ctx context.Context, d api.GameDescription) {
+	a.record(ctx, func(r *record.Recorder) error {
+		return r.SetPlayers(d.Nick, d.Desc, d.Opponent, d.OppDesc)
+	})
+}
+
+// stopRecording closes the recording of the game
+func (a *App) stopRecording() {
+	a.recordMu.Lock()
+	defer a.recordMu.Unlock()
+	if a.recorder == nil {
+		return
+	}
+	if err := a.recorder.Close(); err != nil {
+		a.gui.gui.Log("Could not record the game: %v", err)
+	}
+	a.recorder = nil
+}
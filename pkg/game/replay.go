@@ -0,0 +1,379 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	tl "github.com/grupawp/termloop"
+	gui "github.com/grupawp/warships-gui/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/notation"
+	"warships/pkg/record"
+	"warships/pkg/replay"
+	"warships/pkg/strategy"
+	"warships/pkg/targeting"
+)
+
+var replayHelp = []string{
+	"n / right  - next shot",
+	"b / left   - previous shot",
+	"s / e      - start / end of the game",
+	"N enter    - jump to turn N",
+	"space      - play / pause",
+	"+ / -      - play faster / slower",
+	"h / m      - hints / heatmap",
+	"ctrl+c     - back",
+}
+
+// replaySpeeds are the delays between the shots of the autoplay
+var replaySpeeds = []time.Duration{
+	2 * time.Second, time.Second, 500 * time.Millisecond, 250 * time.Millisecond, 100 * time.Millisecond,
+}
+
+var (
+	// shotBg marks the field the player fired at next
+	shotBg = tl.RgbTo256Color(0, 170, 200)
+	// goodShotBg marks a hinted field the player fired at next
+	goodShotBg = tl.RgbTo256Color(40, 180, 60)
+)
+
+// viewer shows a recorded game on the two boards of the game GUI
+type viewer struct {
+	replay *replay.Replay
+	pos    replay.Position
+	// strategy numbers the recommended fields
+	strategy  strategy.Strategy
+	hintCount int
+	hints     bool
+	heatmap   bool
+	playing   bool
+	speed     int
+	// turn holds the digits of the turn to jump to
+	turn string
+
+	gui           *gui.GUI
+	playerBoard   *gui.Board
+	opponentBoard *gui.Board
+	overlay       *overlay
+	title         *gui.Text
+	status        *gui.Text
+	last          *gui.Text
+	accuracy      *gui.Text
+	advice        *gui.Text
+	message       *gui.Text
+	shipsLeft     map[int]*gui.Text
+}
+
+func newViewer(r *replay.Replay, s strategy.Strategy, hintCount int) *viewer {
+	v := &viewer{
+		replay:        r,
+		strategy:      s,
+		hintCount:     hintCount,
+		speed:         1,
+		gui:           gui.NewGUI(false),
+		playerBoard:   gui.NewBoard(playerBoardX, playerBoardY, nil),
+		opponentBoard: gui.NewBoard(opponentBoardX, opponentBoardY, nil),
+		overlay:       newOverlay(opponentBoardX, opponentBoardY),
+		title:         gui.NewText(timerX, timerY, "", nil),
+		status:        gui.NewText(1, 2, "", nil),
+		last:          gui.NewText(1, 3, "", nil),
+		accuracy:      gui.NewText(opponentBoardX, 3, "", nil),
+		advice:        gui.NewText(hintX, hintY, "", nil),
+		message:       gui.NewText(errorX, errorY, "", nil),
+		shipsLeft:     map[int]*gui.Text{},
+	}
+	h := r.Header
+	v.gui.Draw(v.playerBoard)
+	v.gui.Draw(v.opponentBoard)
+	v.gui.Draw(v.overlay)
+	v.gui.Draw(gui.NewText(playerNickX, playerNickY, h.Nick, nil))
+	v.gui.Draw(gui.NewText(playerDescX, playerDescY, h.Desc, nil))
+	v.gui.Draw(gui.NewText(opponentNickX, opponentNickY, h.Opponent, nil))
+	v.gui.Draw(gui.NewText(opponentDescX, opponentDescY, h.OppDesc, nil))
+	v.gui.Draw(gui.NewText(legendX, legendY, "H - Hit", nil))
+	v.gui.Draw(gui.NewText(legendX, legendY+1, "M - Miss", nil))
+	v.gui.Draw(gui.NewText(legendX, legendY+2, "S - Ship", nil))
+	v.gui.Draw(gui.NewText(legendX, legendY+3, "~ - Empty", nil))
+	for i, l := range r.Config.Fleet.Sizes() {
+		v.shipsLeft[l] = gui.NewText(legendX, legendY+6+i, "", nil)
+		v.gui.Draw(v.shipsLeft[l])
+	}
+	for i, line := range replayHelp {
+		v.gui.Draw(gui.NewText(hintX, hintY+2+i, line, nil))
+	}
+	for _, t := range []*gui.Text{v.title, v.status, v.last, v.accuracy, v.advice, v.message} {
+		v.gui.Draw(t)
+	}
+	v.title.SetText(replayTitle(h, r.Outcome))
+	return v
+}
+
+func replayTitle(h record.Header, outcome api.GameOutcome) string {
+	title := fmt.Sprintf("Replay of %s vs %s", h.Nick, h.Opponent)
+	if !h.Started.IsZero() {
+		title += ", " + h.Started.Local().Format("2006-01-02 15:04")
+	}
+	switch outcome {
+	case api.OutcomeWin:
+		title += ", won"
+	case api.OutcomeLose:
+		title += ", lost"
+	default:
+		title += ", not finished"
+	}
+	return title
+}
+
+// seek moves to the position after step shots
+func (v *viewer) seek(step int) {
+	v.pos = v.replay.At(step)
+	if v.pos.Next == nil {
+		v.playing = false
+	}
+}
+
+// key applies a pressed key
+func (v *viewer) key(k rune) {
+	v.message.SetText("")
+	switch k {
+	case 'n', keyRight:
+		v.seek(v.pos.Step + 1)
+	case 'b', keyLeft:
+		v.seek(v.pos.Step - 1)
+	case 's', keyHome:
+		v.seek(0)
+	case 'e', keyEnd:
+		v.seek(v.replay.Len())
+	case ' ':
+		v.playing = !v.playing
+		if v.pos.Next == nil {
+			v.seek(0)
+		}
+	case '+', '=':
+		if v.speed < len(replaySpeeds)-1 {
+			v.speed++
+		}
+	case '-':
+		if v.speed > 0 {
+			v.speed--
+		}
+	case 'h':
+		v.hints = !v.hints
+	case 'm':
+		v.heatmap = !v.heatmap
+	case keyBackspace:
+		if v.turn != "" {
+			v.turn = v.turn[:len(v.turn)-1]
+		}
+	case keyEnter:
+		n, err := strconv.Atoi(v.turn)
+		v.turn = ""
+		if err != nil {
+			return
+		}
+		step, err := v.replay.TurnStart(n)
+		if err != nil {
+			v.message.SetText(err.Error())
+			return
+		}
+		v.seek(step)
+	default:
+		if k >= '0' && k <= '9' && len(v.turn) < 4 {
+			v.turn += string(k)
+		}
+	}
+}
+
+// redraw shows the current position
+func (v *viewer) redraw() {
+	p := v.pos
+	v.playerBoard.SetStates(mapStatesToGuiMarks(p.PlayerBoard))
+	v.opponentBoard.SetStates(mapStatesToGuiMarks(p.OppBoard))
+	for l, t := range v.shipsLeft {
+		t.SetText(shipsLeftText(p.Remaining[l], l))
+	}
+	v.accuracy.SetText(fmt.Sprintf("Accuracy: %s %%", getAccuracy(p.Hits, p.Shots)))
+
+	status := fmt.Sprintf("Turn %d/%d, shot %d/%d", p.Turn(), v.replay.Turns(), p.Step, v.replay.Len())
+	if v.playing {
+		status += fmt.Sprintf(", playing every %v", replaySpeeds[v.speed])
+	}
+	if v.turn != "" {
+		status += ", go to turn " + v.turn
+	}
+	v.status.SetText(status)
+	v.last.SetText("")
+	if s := p.Last; s != nil {
+		who := "You"
+		if s.Opponent {
+			who = v.replay.Header.Opponent
+		}
+		last := fmt.Sprintf("%s fired at %v: %s", who, s.Coord, s.Result)
+		// imported games do not know when the shots were fired
+		if !s.Time.IsZero() {
+			last = s.Time.Local().Format(time.TimeOnly) + " " + last
+		}
+		v.last.SetText(last)
+	}
+	v.drawAdvice()
+}
+
+// drawAdvice shows what the targeting engine recommended before the player's
+// next shot and how the shot fired compares
+func (v *viewer) drawAdvice() {
+	pos := v.pos
+	v.overlay.clear()
+	v.advice.SetText("")
+	if !v.hints && !v.heatmap || pos.Remaining.Ships() == 0 {
+		return
+	}
+	heat, ranked := recommend(v.strategy, v.replay.Config, pos.OppBoard, pos.Remaining)
+	if v.heatmap {
+		v.overlay.paintHeatmap(heat)
+	}
+	var top []targeting.Rated
+	if v.hints {
+		top = v.overlay.paintHints(ranked, v.hintCount)
+	}
+	next := pos.Next
+	if next == nil || next.Opponent {
+		if len(top) > 0 {
+			v.advice.SetText(fmt.Sprintf("Hint: %v (%.0f%%)", top[0].Coord, top[0].P*100))
+		}
+		return
+	}
+	bg := shotBg
+	for i, r := range top {
+		if r.Coord == next.Coord {
+			bg = goodShotBg
+			v.overlay.set(r.Coord, fmt.Sprintf(" %d ", i+1), hintFg, bg)
+		}
+	}
+	if bg == shotBg {
+		v.overlay.set(next.Coord, " * ", hintFg, bg)
+	}
+	best, _ := heat.Best()
+	p := heat.P(next.Coord)
+	if p == 0 {
+		v.advice.SetText(fmt.Sprintf("Next: %v, no ship fits there, best %v %.0f%%", next.Coord, best, heat.Max()*100))
+		return
+	}
+	all := heat.Ranked()
+	rank := sort.Search(len(all), func(i int) bool { return all[i].P <= p }) + 1
+	v.advice.SetText(fmt.Sprintf("Next: %v, %.0f%%, rank %d of %d, best %v %.0f%%",
+		next.Coord, p*100, rank, len(all), best, heat.Max()*100))
+}
+
+// run shows the replay until the player goes back with ctrl+c
+func (v *viewer) run(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	keys := newKeyListener()
+	v.gui.Draw(keys)
+	v.seek(0)
+
+	go func() {
+		timer := time.NewTimer(replaySpeeds[v.speed])
+		defer timer.Stop()
+		for {
+			v.redraw()
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(replaySpeeds[v.speed])
+			select {
+			case <-ctx.Done():
+				return
+			case k := <-keys.Keys():
+				v.key(k)
+			case <-timer.C:
+				if v.playing {
+					v.seek(v.pos.Step + 1)
+				}
+			}
+		}
+	}()
+	v.gui.Start(ctx, nil)
+}
+
+// recordings returns the recorded games, the latest first
+func (a *App) recordings() ([]string, error) {
+	a.recordMu.Lock()
+	dir := a.recordDir
+	a.recordMu.Unlock()
+	if dir == "" {
+		return nil, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	// the names start with the time the game started
+	sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	return files, nil
+}
+
+// ReplayGame lets the player pick a recorded game and replays it
+func (a *App) ReplayGame(ctx context.Context) {
+	files, err := a.recordings()
+	if err != nil {
+		fmt.Println("Could not list the recorded games:", err)
+		return
+	}
+	if len(files) == 0 {
+		fmt.Println("No games recorded yet")
+		return
+	}
+	const listed = 20
+	for i, f := range files {
+		if i == listed {
+			fmt.Printf("... and %d older game(s)\n", len(files)-listed)
+			break
+		}
+		line := fmt.Sprintf("%2d. %s", i+1, filepath.Base(f))
+		if g, err := record.Load(f); err == nil {
+			line += fmt.Sprintf(" vs %s", g.Header.Opponent)
+			if o := g.Outcome(); o != api.OutcomeNone {
+				line += ", " + string(o)
+			}
+		}
+		fmt.Println(line)
+	}
+	fmt.Println("Enter a number, a file name or a game code: ")
+	answer := readLine()
+	path := answer
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(files) {
+		path = files[n-1]
+	}
+	if err := a.Replay(ctx, path); err != nil {
+		fmt.Println("Could not replay the game:", err)
+	}
+}
+
+// Replay shows a game read from a recording, a file in the notation or a game code
+func (a *App) Replay(ctx context.Context, game string) error {
+	if strings.TrimSpace(game) == "" {
+		return os.ErrNotExist
+	}
+	g, err := notation.Load(game)
+	if err != nil {
+		return err
+	}
+	r, err := replay.New(g)
+	if err != nil {
+		return err
+	}
+	a.gui.mu.Lock()
+	s, n := a.gui.hintStrategy, a.gui.hintCount
+	a.gui.mu.Unlock()
+	newViewer(r, s, n).run(ctx)
+	return nil
+}
@@ -0,0 +1,209 @@
+package layout
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+)
+
+// A layout is written either as a grid or as a list of ships.
+//
+// The grid has one line per row, S, X or O marks a ship and . or ~ water.
+// A header of column letters and row numbers in front of the rows are
+// optional, so are spaces between the fields:
+//
+//	   ABCDEFGHIJ
+//	 1 SSSS......
+//	 2 ..........
+//
+//	   A B C D E F G H I J
+//	 1 S S S S . . . . . .
+//
+// The list holds ships separated by spaces or commas, a ship is a single
+// field or a range of fields in one row or column, e.g. A1-A4 C3 E5-G5.
+//
+// In both forms lines starting with # and followed by a space are comments.
+
+// List formats l as a list of ships, longest first, e.g. A1-A4 C1-C3 E1
+func (l Layout) List() string {
+	set := map[coord.Coord]bool{}
+	for _, c := range l {
+		set[c] = true
+	}
+	var parts []string
+	for _, ship := range rules.Ships(set) {
+		if len(ship) == 1 {
+			parts = append(parts, ship[0].String())
+			continue
+		}
+		parts = append(parts, ship[0].String()+"-"+ship[len(ship)-1].String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Parse reads a layout in either form and validates it against cfg
+func Parse(text string, cfg rules.Config) (Layout, error) {
+	if isGrid(text) {
+		return ParseGrid(text, cfg)
+	}
+	return ParseList(text, cfg)
+}
+
+// ParseList reads a list of ships and validates it against cfg
+func ParseList(text string, cfg rules.Config) (Layout, error) {
+	var l Layout
+	for _, line := range lines(text) {
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == ','
+		})
+		for _, f := range fields {
+			cells, err := parseRange(f, cfg)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, cells...)
+		}
+	}
+	if err := l.Validate(cfg); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// ParseGrid reads a grid and validates it against cfg
+func ParseGrid(text string, cfg rules.Config) (Layout, error) {
+	var l Layout
+	y := 0
+	for _, line := range lines(text) {
+		row := strings.TrimSpace(line)
+		if isHeader(row) {
+			continue
+		}
+		// drop the row number and the spaces between fields
+		row = strings.TrimLeft(row, "0123456789")
+		row = strings.Join(strings.Fields(row), "")
+		if y >= cfg.Height {
+			return nil, fmt.Errorf("grid has more than %d rows", cfg.Height)
+		}
+		if len(row) != cfg.Width {
+			return nil, fmt.Errorf("row %d has %d fields, expected %d", y+1, len(row), cfg.Width)
+		}
+		for x, ch := range row {
+			switch ch {
+			case 'S', 's', 'X', 'x', 'O', 'o':
+				l = append(l, coord.FromIndex(x, y))
+			case '.', '~':
+			default:
+				return nil, fmt.Errorf("row %d: unexpected %q", y+1, ch)
+			}
+		}
+		y++
+	}
+	if y != cfg.Height {
+		return nil, fmt.Errorf("grid has %d rows, expected %d", y, cfg.Height)
+	}
+	if err := l.Validate(cfg); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Load reads a layout file in either form and validates it against cfg
+func Load(path string, cfg rules.Config) (Layout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l, err := Parse(string(data), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Save writes l as a grid preceded by its list as a comment, which both
+// forms of the parser read back
+func Save(path string, l Layout, cfg rules.Config) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", l.List())
+	b.WriteString(l.Draw(cfg))
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// parseRange parses a field, e.g. A1, or a straight range of fields, e.g. A1-A4
+func parseRange(s string, cfg rules.Config) ([]coord.Coord, error) {
+	from, to, isRange := strings.Cut(s, "-")
+	a, err := cfg.Parse(from)
+	if err != nil {
+		return nil, err
+	}
+	if !isRange {
+		return []coord.Coord{a}, nil
+	}
+	b, err := cfg.Parse(to)
+	if err != nil {
+		return nil, err
+	}
+	if a.X != b.X && a.Y != b.Y {
+		return nil, fmt.Errorf("%s is not a row or a column: %w", s, rules.ErrNotStraight)
+	}
+	dx, dy := sign(b.X-a.X), sign(b.Y-a.Y)
+	cells := []coord.Coord{a}
+	for c := a; c != b; {
+		c = c.Add(dx, dy)
+		cells = append(cells, c)
+	}
+	return cells, nil
+}
+
+// lines returns the lines of text without comments and blank lines
+func lines(text string) []string {
+	var out []string
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "#" || strings.HasPrefix(trimmed, "# ") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// isGrid tells a grid from a list: only a grid has water
+func isGrid(text string) bool {
+	for _, line := range lines(text) {
+		if strings.ContainsAny(line, ".~") {
+			return true
+		}
+	}
+	return false
+}
+
+// isHeader reports the row of column letters, A B C ...
+func isHeader(row string) bool {
+	row = strings.ReplaceAll(row, " ", "")
+	if row == "" {
+		return false
+	}
+	for i, ch := range strings.ToUpper(row) {
+		if ch != rune('A'+i) {
+			return false
+		}
+	}
+	return true
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
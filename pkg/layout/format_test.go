@@ -0,0 +1,104 @@
+package layout
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+)
+
+func TestRoundTrip(t *testing.T) {
+	formats := []struct {
+		name  string
+		parse func(l Layout, cfg rules.Config) (Layout, error)
+	}{
+		{"list", func(l Layout, cfg rules.Config) (Layout, error) {
+			return Parse(l.List(), cfg)
+		}},
+		{"comma list", func(l Layout, cfg rules.Config) (Layout, error) {
+			return Parse(strings.ReplaceAll(l.List(), " ", ", "), cfg)
+		}},
+		{"grid", func(l Layout, cfg rules.Config) (Layout, error) {
+			return Parse(l.Draw(cfg), cfg)
+		}},
+		{"spaced grid", func(l Layout, cfg rules.Config) (Layout, error) {
+			return Parse(spaced(l.Draw(cfg)), cfg)
+		}},
+		{"file", func(l Layout, cfg rules.Config) (Layout, error) {
+			path := filepath.Join(t.TempDir(), "layout.txt")
+			if err := Save(path, l, cfg); err != nil {
+				return nil, err
+			}
+			return Load(path, cfg)
+		}},
+	}
+	for _, cfg := range []rules.Config{rules.Classic(), rules.Hasbro(), rules.Training()} {
+		l, err := NewGenerator(cfg, 1).Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range formats {
+			t.Run(cfg.Name+"/"+f.name, func(t *testing.T) {
+				got, err := f.parse(l, cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !same(got, l) {
+					t.Errorf("got %v, want %v", got.List(), l.List())
+				}
+			})
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	cfg := rules.Training()
+	l, err := NewGenerator(cfg, 1).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	grid := l.Draw(cfg)
+	rows := strings.Split(strings.TrimSuffix(grid, "\n"), "\n")
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"missing ship", l[1:].List()},
+		{"diagonal range", "A1-C3"},
+		{"off the board", strings.Replace(l.List(), l[0].String(), "Z1", 1)},
+		{"padded field", strings.Replace(l.List(), l[0].String(), l[0].String()[:1]+"0"+l[0].String()[1:], 1)},
+		{"short row", strings.Replace(grid, rows[1], rows[1][:len(rows[1])-1], 1)},
+		{"missing row", strings.Join(rows[:len(rows)-1], "\n")},
+		{"extra row", grid + rows[len(rows)-1]},
+		{"unexpected character", strings.Replace(grid, ".", "?", 1)},
+		{"touching ships", strings.Replace(grid, ".", "S", -1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Parse(tt.text, cfg); err == nil {
+				t.Errorf("parsed %v", got.List())
+			}
+		})
+	}
+}
+
+// spaced puts a space between the fields of every line of a drawn grid
+func spaced(grid string) string {
+	lines := strings.Split(strings.TrimSuffix(grid, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = line[:3] + strings.Join(strings.Split(line[3:], ""), " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func same(a, b Layout) bool {
+	key := func(l Layout) []string {
+		s := coord.Strings(l)
+		sort.Strings(s)
+		return s
+	}
+	return strings.Join(key(a), " ") == strings.Join(key(b), " ")
+}
@@ -0,0 +1,340 @@
+package layout
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+)
+
+// ErrNoLayout is returned when the fleet does not fit on the board
+var ErrNoLayout = errors.New("no legal layout found")
+
+// maxAttempts bounds the work spent on a single layout
+const maxAttempts = 1 << 20
+
+// Bias steers the strategic generator, every weight is neutral at zero and
+// useful values lie roughly between -3 and 3
+type Bias struct {
+	// Edges above zero puts ships along the border of the board, below zero keeps them off it
+	Edges float64
+	// Spread above zero distributes ships evenly over the four quarters of the board
+	Spread float64
+	// Clustering above zero packs ships close together, below zero pushes them apart
+	Clustering float64
+}
+
+// DefaultBias hugs the edges and spreads ships apart, which leaves the
+// centre empty where probability based shooters look first
+func DefaultBias() Bias {
+	return Bias{Edges: 1.5, Spread: 1, Clustering: -1}
+}
+
+// Styles returns named biases for the strategic generator
+func Styles() map[string]Bias {
+	return map[string]Bias{
+		"edge-hugger": {Edges: 3},
+		"scatter":     {Spread: 2, Clustering: -2},
+		"anti-hunt":   DefaultBias(),
+	}
+}
+
+// Generator produces random legal layouts of a variant. The same seed yields
+// the same sequence of layouts.
+type Generator struct {
+	cfg       rules.Config
+	rnd       *rand.Rand
+	strategic bool
+	bias      Bias
+}
+
+// NewGenerator returns a generator drawing uniformly from all legal layouts of cfg
+func NewGenerator(cfg rules.Config, seed int64) *Generator {
+	return &Generator{cfg: cfg, rnd: rand.New(rand.NewSource(seed))}
+}
+
+// NewStrategicGenerator returns a generator placing ships one by one,
+// choosing among the legal placements of each ship with weights given by bias
+func NewStrategicGenerator(cfg rules.Config, seed int64, bias Bias) *Generator {
+	g := NewGenerator(cfg, seed)
+	g.strategic = true
+	g.bias = bias
+	return g
+}
+
+// Generate returns the next layout
+func (g *Generator) Generate() (Layout, error) {
+	if err := g.cfg.Check(); err != nil {
+		return nil, err
+	}
+	if g.strategic {
+		return g.strategicLayout()
+	}
+	return g.uniformLayout()
+}
+
+// Complete adds randomly placed ships to placed until the fleet is full,
+// placed must be a legal part of a layout
+func (g *Generator) Complete(placed Layout) (Layout, error) {
+	if err := g.cfg.Check(); err != nil {
+		return nil, err
+	}
+	set := map[coord.Coord]bool{}
+	for _, c := range placed {
+		set[c] = true
+	}
+	ships := rules.Ships(set)
+	missing := g.cfg.Fleet.Clone()
+	var cells []coord.Coord
+	for _, ship := range ships {
+		if err := rules.CheckShip(g.cfg, ship, cells); err != nil {
+			return nil, err
+		}
+		cells = append(cells, ship...)
+		missing[len(ship)]--
+		if missing[len(ship)] < 0 {
+			return nil, &rules.FleetError{Length: len(ship), Want: g.cfg.Fleet[len(ship)], Got: g.cfg.Fleet[len(ship)] - missing[len(ship)]}
+		}
+	}
+
+	start := make([]bool, g.cfg.Width*g.cfg.Height)
+	for _, ship := range ships {
+		for _, c := range ship {
+			g.block(placement{c: c, n: 1}, start)
+		}
+	}
+	blocked := make([]bool, len(start))
+	for attempt := 0; attempt < maxAttempts/1024; attempt++ {
+		copy(blocked, start)
+		l := append(Layout{}, cells...)
+		ok := true
+		for _, n := range missing.Lengths() {
+			var legal []placement
+			for _, p := range placements(g.cfg, n) {
+				if g.free(p, blocked) {
+					legal = append(legal, p)
+				}
+			}
+			if len(legal) == 0 {
+				ok = false
+				break
+			}
+			p := legal[g.rnd.Intn(len(legal))]
+			g.block(p, blocked)
+			l = append(l, p.cells()...)
+		}
+		if ok {
+			return l, nil
+		}
+	}
+	return nil, ErrNoLayout
+}
+
+// placement is a ship of length n starting at c and running right or down
+type placement struct {
+	c    coord.Coord
+	n    int
+	down bool
+}
+
+func (p placement) cells() []coord.Coord {
+	cells := make([]coord.Coord, p.n)
+	for i := range cells {
+		if p.down {
+			cells[i] = p.c.Add(0, i)
+		} else {
+			cells[i] = p.c.Add(i, 0)
+		}
+	}
+	return cells
+}
+
+// placements returns every placement of a ship of length n on the board of
+// cfg, each one exactly once
+func placements(cfg rules.Config, n int) []placement {
+	var ps []placement
+	for _, c := range cfg.Fields() {
+		if c.X+n <= cfg.Width {
+			ps = append(ps, placement{c, n, false})
+		}
+		if n > 1 && c.Y+n <= cfg.Height {
+			ps = append(ps, placement{c, n, true})
+		}
+	}
+	return ps
+}
+
+// uniformLayout places every ship independently and uniformly and rejects
+// illegal results, so that every legal layout is equally likely
+func (g *Generator) uniformLayout() (Layout, error) {
+	lengths := g.cfg.Fleet.Lengths()
+	options := map[int][]placement{}
+	for _, n := range lengths {
+		if options[n] == nil {
+			options[n] = placements(g.cfg, n)
+		}
+		if len(options[n]) == 0 {
+			return nil, ErrNoLayout
+		}
+	}
+
+	blocked := make([]bool, g.cfg.Width*g.cfg.Height)
+	chosen := make([]placement, len(lengths))
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		for i := range blocked {
+			blocked[i] = false
+		}
+		ok := true
+		for i, n := range lengths {
+			p := options[n][g.rnd.Intn(len(options[n]))]
+			if !g.free(p, blocked) {
+				ok = false
+				break
+			}
+			g.block(p, blocked)
+			chosen[i] = p
+		}
+		if ok {
+			var l Layout
+			for _, p := range chosen {
+				l = append(l, p.cells()...)
+			}
+			return l, nil
+		}
+	}
+	return nil, ErrNoLayout
+}
+
+// strategicLayout places ships longest first, picking each placement with
+// a probability growing exponentially with its biased score
+func (g *Generator) strategicLayout() (Layout, error) {
+	lengths := g.cfg.Fleet.Lengths()
+	for attempt := 0; attempt < maxAttempts/1024; attempt++ {
+		blocked := make([]bool, g.cfg.Width*g.cfg.Height)
+		var l Layout
+		ok := true
+		for _, n := range lengths {
+			var legal []placement
+			var weights []float64
+			total := 0.0
+			for _, p := range placements(g.cfg, n) {
+				if !g.free(p, blocked) {
+					continue
+				}
+				w := math.Exp(g.score(p.cells(), l))
+				legal = append(legal, p)
+				weights = append(weights, w)
+				total += w
+			}
+			if len(legal) == 0 {
+				ok = false
+				break
+			}
+			r := g.rnd.Float64() * total
+			i := 0
+			for ; i < len(legal)-1 && r >= weights[i]; i++ {
+				r -= weights[i]
+			}
+			g.block(legal[i], blocked)
+			l = append(l, legal[i].cells()...)
+		}
+		if ok {
+			return l, nil
+		}
+	}
+	return nil, ErrNoLayout
+}
+
+// score rates placing a ship on cells next to the ships already in placed
+func (g *Generator) score(cells []coord.Coord, placed Layout) float64 {
+	w, h := g.cfg.Width, g.cfg.Height
+
+	edge := 0.0
+	for _, c := range cells {
+		if c.X == 0 || c.Y == 0 || c.X == w-1 || c.Y == h-1 {
+			edge++
+		}
+	}
+	edge /= float64(len(cells))
+
+	if len(placed) == 0 {
+		return g.bias.Edges * edge
+	}
+
+	// share of the fleet already in the quarters this ship would go to
+	var quarters [4]float64
+	for _, c := range placed {
+		quarters[quarter(c, w, h)]++
+	}
+	crowd := 0.0
+	for _, c := range cells {
+		crowd += quarters[quarter(c, w, h)] / float64(len(placed))
+	}
+	crowd /= float64(len(cells))
+
+	// distance to the nearest ship, relative to the board size
+	nearest := math.MaxFloat64
+	for _, c := range cells {
+		for _, p := range placed {
+			nearest = math.Min(nearest, float64(maxInt(abs(c.X-p.X), abs(c.Y-p.Y))))
+		}
+	}
+	closeness := 1 - nearest/float64(maxInt(w, h))
+
+	return g.bias.Edges*edge + g.bias.Spread*(1-crowd) + g.bias.Clustering*closeness
+}
+
+// free reports whether p only covers fields not blocked by other ships,
+// blocked is indexed by x*height+y
+func (g *Generator) free(p placement, blocked []bool) bool {
+	dx, dy := 1, 0
+	if p.down {
+		dx, dy = 0, 1
+	}
+	for i := 0; i < p.n; i++ {
+		if blocked[(p.c.X+dx*i)*g.cfg.Height+p.c.Y+dy*i] {
+			return false
+		}
+	}
+	return true
+}
+
+// block marks the fields of p and the fields around them as unavailable to other ships
+func (g *Generator) block(p placement, blocked []bool) {
+	w, h := g.cfg.Width, g.cfg.Height
+	for _, c := range p.cells() {
+		for x := c.X - 1; x <= c.X+1; x++ {
+			for y := c.Y - 1; y <= c.Y+1; y++ {
+				if x >= 0 && x < w && y >= 0 && y < h {
+					blocked[x*h+y] = true
+				}
+			}
+		}
+	}
+}
+
+func quarter(c coord.Coord, w, h int) int {
+	q := 0
+	if 2*c.X >= w {
+		q++
+	}
+	if 2*c.Y >= h {
+		q += 2
+	}
+	return q
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,138 @@
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+)
+
+// generators builds every kind of generator for cfg with seed
+func generators(cfg rules.Config, seed int64) map[string]*Generator {
+	gs := map[string]*Generator{"uniform": NewGenerator(cfg, seed)}
+	for name, bias := range Styles() {
+		gs[name] = NewStrategicGenerator(cfg, seed, bias)
+	}
+	return gs
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	cfg := rules.Classic()
+	a, b := generators(cfg, 7), generators(cfg, 7)
+	for name := range a {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 5; i++ {
+				la, err := a[name].Generate()
+				if err != nil {
+					t.Fatal(err)
+				}
+				lb, err := b[name].Generate()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(la, lb) {
+					t.Fatalf("layout %d differs: %v and %v", i, la, lb)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateValid(t *testing.T) {
+	for _, variant := range rules.Variants() {
+		cfg, err := rules.Variant(variant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, g := range generators(cfg, 1) {
+			t.Run(fmt.Sprintf("%s %s", variant, name), func(t *testing.T) {
+				for i := 0; i < 20; i++ {
+					l, err := g.Generate()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if _, err := rules.Validate(cfg, l); err != nil {
+						t.Fatalf("%v in\n%s", err, l.Draw(cfg))
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tiny := rules.Config{Name: "tiny", Width: 3, Height: 3, Fleet: rules.Fleet{2: 2}}
+	tests := []struct {
+		name   string
+		cfg    rules.Config
+		placed []string
+		want   error
+	}{
+		{name: "nothing placed", cfg: rules.Classic()},
+		{name: "some ships placed", cfg: rules.Classic(), placed: []string{"A1", "A2", "A3", "A4", "C1", "E5", "F5"}},
+		{name: "full fleet placed", cfg: rules.Training(), placed: []string{"A1", "A2", "A3", "C1", "C2", "E1", "E2", "G1", "G3", "G5"}},
+		{name: "too many ships", cfg: rules.Classic(), placed: []string{"A1", "A2", "A3", "A4", "C1", "C2", "C3", "C4"}, want: &rules.FleetError{}},
+		{name: "bent ship", cfg: rules.Classic(), placed: []string{"A1", "A2", "B2"}, want: rules.ErrNotStraight},
+		{name: "touching ships", cfg: rules.Classic(), placed: []string{"A1", "A2", "B3"}, want: rules.ErrTouching},
+		{name: "no room left", cfg: tiny, placed: []string{"B1", "B2"}, want: ErrNoLayout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placed := Layout{}
+			for _, s := range tt.placed {
+				placed = append(placed, coord.MustParse(s))
+			}
+			l, err := NewGenerator(tt.cfg, 1).Complete(placed)
+			var fe *rules.FleetError
+			switch {
+			case errors.As(tt.want, &fe):
+				if !errors.As(err, &fe) {
+					t.Fatalf("got %v, want a fleet error", err)
+				}
+				return
+			case tt.want != nil:
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("got %v, want %v", err, tt.want)
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if _, err := rules.Validate(tt.cfg, l); err != nil {
+				t.Fatalf("%v in\n%s", err, l.Draw(tt.cfg))
+			}
+			if len(l) < len(placed) || !same(l[:len(placed)], placed) {
+				t.Errorf("got %v, want it to keep %v", l, placed)
+			}
+		})
+	}
+}
+
+func TestStrategicBias(t *testing.T) {
+	cfg := rules.Classic()
+	// edges returns the share of ship fields on the border over many layouts
+	edges := func(g *Generator) float64 {
+		border, total := 0, 0
+		for i := 0; i < 200; i++ {
+			l, err := g.Generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range l {
+				if c.X == 0 || c.Y == 0 || c.X == cfg.Width-1 || c.Y == cfg.Height-1 {
+					border++
+				}
+			}
+			total += len(l)
+		}
+		return float64(border) / float64(total)
+	}
+	off := edges(NewStrategicGenerator(cfg, 1, Bias{Edges: -3}))
+	uniform := edges(NewGenerator(cfg, 1))
+	hug := edges(NewStrategicGenerator(cfg, 1, Styles()["edge-hugger"]))
+	if !(off < uniform && uniform < hug) {
+		t.Errorf("got border shares %.2f off the edges, %.2f uniform and %.2f hugging them", off, uniform, hug)
+	}
+}
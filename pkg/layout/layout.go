@@ -0,0 +1,49 @@
+// Package layout generates, formats and stores fleet layouts.
+package layout
+
+import (
+	"fmt"
+	"strings"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+)
+
+// Layout is the list of fields occupied by the ships of a fleet
+type Layout []coord.Coord
+
+// Strings returns the fields in the server notation, as sent in coords
+func (l Layout) Strings() []string {
+	return coord.Strings(l)
+}
+
+// Validate checks l against the variant cfg
+func (l Layout) Validate(cfg rules.Config) error {
+	_, err := rules.Validate(cfg, l)
+	return err
+}
+
+// Draw renders l on the board of cfg, one row per line: S is a ship and . water
+func (l Layout) Draw(cfg rules.Config) string {
+	ships := map[coord.Coord]bool{}
+	for _, c := range l {
+		ships[c] = true
+	}
+	var b strings.Builder
+	b.WriteString("   ")
+	for x := 0; x < cfg.Width; x++ {
+		b.WriteByte(byte('A' + x))
+	}
+	b.WriteByte('\n')
+	for y := 0; y < cfg.Height; y++ {
+		fmt.Fprintf(&b, "%2d ", y+1)
+		for x := 0; x < cfg.Width; x++ {
+			if ships[coord.FromIndex(x, y)] {
+				b.WriteByte('S')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
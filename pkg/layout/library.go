@@ -0,0 +1,199 @@
+package layout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"warships/pkg/rules"
+)
+
+var (
+	// ErrNoPreset is returned when no preset matches a name or tag
+	ErrNoPreset = errors.New("no such preset")
+	// ErrPresetExists is returned when creating a preset under a taken name
+	ErrPresetExists = errors.New("preset already exists")
+)
+
+// Preset is a named layout kept in a library
+type Preset struct {
+	Name string `json:"name"`
+	// Variant is the name of the variant the layout was made for
+	Variant string   `json:"variant"`
+	Tags    []string `json:"tags,omitempty"`
+	// Profile limits the preset to one player nick, empty shares it with everyone
+	Profile string    `json:"profile,omitempty"`
+	Layout  Layout    `json:"layout"`
+	Created time.Time `json:"created"`
+}
+
+// HasTag reports whether p is tagged with tag, ignoring case
+func (p Preset) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter selects presets, empty fields match everything
+type Filter struct {
+	Variant string
+	// Profile selects the presets of one player together with the shared ones
+	Profile string
+	Tag     string
+}
+
+func (f Filter) match(p Preset) bool {
+	return (f.Variant == "" || p.Variant == f.Variant) &&
+		(p.Profile == "" || f.Profile == "" || p.Profile == f.Profile) &&
+		(f.Tag == "" || p.HasTag(f.Tag))
+}
+
+// Library keeps presets in a single file
+type Library struct {
+	Path string
+}
+
+// Create validates p.Layout against cfg and adds p to the library under its name
+func (lib *Library) Create(p Preset, cfg rules.Config) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("preset needs a name")
+	}
+	if err := p.Layout.Validate(cfg); err != nil {
+		return err
+	}
+	presets, err := lib.load()
+	if err != nil {
+		return err
+	}
+	for _, q := range presets {
+		if strings.EqualFold(q.Name, p.Name) {
+			return fmt.Errorf("%s: %w", p.Name, ErrPresetExists)
+		}
+	}
+	p.Variant = cfg.Name
+	if p.Created.IsZero() {
+		p.Created = time.Now()
+	}
+	return lib.save(append(presets, p))
+}
+
+// Get returns the preset called name, ignoring case
+func (lib *Library) Get(name string) (Preset, error) {
+	presets, err := lib.load()
+	if err != nil {
+		return Preset{}, err
+	}
+	for _, p := range presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, nil
+		}
+	}
+	return Preset{}, fmt.Errorf("%s: %w", name, ErrNoPreset)
+}
+
+// List returns the presets matching f sorted by name
+func (lib *Library) List(f Filter) ([]Preset, error) {
+	presets, err := lib.load()
+	if err != nil {
+		return nil, err
+	}
+	var out []Preset
+	for _, p := range presets {
+		if f.match(p) {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
+	})
+	return out, nil
+}
+
+// Delete removes the preset called name
+func (lib *Library) Delete(name string) error {
+	presets, err := lib.load()
+	if err != nil {
+		return err
+	}
+	for i, p := range presets {
+		if strings.EqualFold(p.Name, name) {
+			return lib.save(append(presets[:i], presets[i+1:]...))
+		}
+	}
+	return fmt.Errorf("%s: %w", name, ErrNoPreset)
+}
+
+// Random returns a preset matching f picked uniformly with rnd
+func (lib *Library) Random(f Filter, rnd *rand.Rand) (Preset, error) {
+	presets, err := lib.List(f)
+	if err != nil {
+		return Preset{}, err
+	}
+	if len(presets) == 0 {
+		if f.Tag != "" {
+			return Preset{}, fmt.Errorf("tag %s: %w", f.Tag, ErrNoPreset)
+		}
+		return Preset{}, ErrNoPreset
+	}
+	return presets[rnd.Intn(len(presets))], nil
+}
+
+// Tags returns every tag used in the library, sorted
+func (lib *Library) Tags() ([]string, error) {
+	presets, err := lib.load()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var tags []string
+	for _, p := range presets {
+		for _, t := range p.Tags {
+			if !seen[strings.ToLower(t)] {
+				seen[strings.ToLower(t)] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (lib *Library) load() ([]Preset, error) {
+	data, err := os.ReadFile(lib.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var presets []Preset
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("%s: %w", lib.Path, err)
+	}
+	return presets, nil
+}
+
+func (lib *Library) save(presets []Preset) error {
+	data, err := json.MarshalIndent(presets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(lib.Path), 0o700); err != nil {
+		return err
+	}
+	// never leave a half written library behind
+	tmp := lib.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, lib.Path)
+}
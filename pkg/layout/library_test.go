@@ -0,0 +1,171 @@
+package layout
+
+import (
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"warships/pkg/rules"
+)
+
+// newLibrary returns a library in a fresh directory holding presets, each
+// with a generated layout of its variant
+func newLibrary(t *testing.T, presets ...Preset) *Library {
+	t.Helper()
+	lib := &Library{Path: filepath.Join(t.TempDir(), "presets", "library.json")}
+	for i, p := range presets {
+		cfg, err := rules.Variant(p.Variant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Layout, err = NewGenerator(cfg, int64(i+1)).Generate(); err != nil {
+			t.Fatal(err)
+		}
+		if err := lib.Create(p, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return lib
+}
+
+func TestLibrarySaveLoad(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	lib := newLibrary(t,
+		Preset{Name: "corner", Variant: "classic", Tags: []string{"edge"}, Profile: "ann", Created: created},
+		Preset{Name: "small", Variant: "training"},
+	)
+
+	reopened := &Library{Path: lib.Path}
+	p, err := reopened.Get("CORNER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "corner" || p.Variant != "classic" || p.Profile != "ann" || !reflect.DeepEqual(p.Tags, []string{"edge"}) || !p.Created.Equal(created) {
+		t.Errorf("got preset %+v", p)
+	}
+	if err := p.Layout.Validate(rules.Classic()); err != nil {
+		t.Errorf("loaded layout: %v", err)
+	}
+	if p, err := reopened.Get("small"); err != nil || p.Created.IsZero() {
+		t.Errorf("got preset %+v, %v", p, err)
+	}
+
+	if _, err := reopened.Get("missing"); !errors.Is(err, ErrNoPreset) {
+		t.Errorf("got %v, want %v", err, ErrNoPreset)
+	}
+	if err := reopened.Delete("Corner"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lib.Get("corner"); !errors.Is(err, ErrNoPreset) {
+		t.Errorf("got %v after deleting, want %v", err, ErrNoPreset)
+	}
+	if err := lib.Delete("corner"); !errors.Is(err, ErrNoPreset) {
+		t.Errorf("deleted twice, got %v", err)
+	}
+}
+
+func TestLibraryCreate(t *testing.T) {
+	cfg := rules.Classic()
+	valid, err := NewGenerator(cfg, 1).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	isFleetError := func(err error) bool {
+		var fe *rules.FleetError
+		return errors.As(err, &fe)
+	}
+	tests := []struct {
+		name    string
+		preset  Preset
+		wantErr func(error) bool
+	}{
+		{"same name", Preset{Name: "corner", Layout: valid}, exists},
+		{"name in another case", Preset{Name: " Corner ", Layout: valid}, exists},
+		{"no name", Preset{Name: "  ", Layout: valid}, func(err error) bool { return err != nil && !exists(err) }},
+		{"invalid layout", Preset{Name: "broken", Layout: valid[1:]}, isFleetError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lib := newLibrary(t, Preset{Name: "corner", Variant: "classic"})
+			if err := lib.Create(tt.preset, cfg); !tt.wantErr(err) {
+				t.Errorf("got error %v", err)
+			}
+			if presets, err := lib.List(Filter{}); err != nil || len(presets) != 1 {
+				t.Errorf("got presets %v, %v", presets, err)
+			}
+		})
+	}
+}
+
+func TestLibraryList(t *testing.T) {
+	lib := newLibrary(t,
+		Preset{Name: "alpha", Variant: "classic", Tags: []string{"edge"}},
+		Preset{Name: "Beta", Variant: "classic", Tags: []string{"Edge", "scatter"}, Profile: "ann"},
+		Preset{Name: "gamma", Variant: "training", Profile: "bob"},
+		Preset{Name: "delta", Variant: "training"},
+	)
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"everything", Filter{}, []string{"alpha", "Beta", "delta", "gamma"}},
+		{"variant", Filter{Variant: "training"}, []string{"delta", "gamma"}},
+		{"profile with shared presets", Filter{Profile: "ann"}, []string{"alpha", "Beta", "delta"}},
+		{"profile and variant", Filter{Profile: "bob", Variant: "training"}, []string{"delta", "gamma"}},
+		{"tag in any case", Filter{Tag: "EDGE"}, []string{"alpha", "Beta"}},
+		{"tag of another profile", Filter{Tag: "scatter", Profile: "bob"}, nil},
+		{"unknown variant", Filter{Variant: "hasbro"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			presets, err := lib.List(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, p := range presets {
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("got %v, want %v", names, tt.want)
+			}
+
+			p, err := lib.Random(tt.filter, rand.New(rand.NewSource(1)))
+			switch {
+			case len(tt.want) == 0 && !errors.Is(err, ErrNoPreset):
+				t.Errorf("random: got %+v, %v", p, err)
+			case len(tt.want) > 0 && (err != nil || !tt.filter.match(p)):
+				t.Errorf("random: got %+v, %v", p, err)
+			}
+		})
+	}
+
+	tags, err := lib.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"edge", "scatter"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("got tags %v, want %v", tags, want)
+	}
+}
+
+func TestLibraryCorrupt(t *testing.T) {
+	lib := &Library{Path: filepath.Join(t.TempDir(), "library.json")}
+	if presets, err := lib.List(Filter{}); err != nil || len(presets) != 0 {
+		t.Errorf("missing file: got %v, %v", presets, err)
+	}
+	if err := os.WriteFile(lib.Path, []byte(`[{"name": "cut`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lib.Get("cut"); err == nil {
+		t.Error("read a corrupt library")
+	}
+}
+
+func exists(err error) bool {
+	return errors.Is(err, ErrPresetExists)
+}
@@ -0,0 +1,243 @@
+package notation
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/coord"
+	"warships/pkg/record"
+	"warships/pkg/replay"
+	"warships/pkg/rules"
+)
+
+// A game code packs a game into bits and encodes them as URL safe base64:
+// the version, the rules, the nicks, the start time, the layouts as one bit
+// per field and every shot as its field and result. The turn rule tells
+// whose shot it is, so only the side that opened the game is stored. The
+// descriptions are left out to keep the code short.
+
+// codeVersion is the version of the codes written
+const codeVersion = 1
+
+// ErrCode is returned for a malformed game code
+var ErrCode = errors.New("invalid game code")
+
+var codeResults = []api.ShotResult{api.ShotMiss, api.ShotHit, api.ShotSunk}
+
+var codeOutcomes = []api.GameOutcome{api.OutcomeNone, api.OutcomeWin, api.OutcomeLose}
+
+// EncodeCode returns the game code of g
+func EncodeCode(g record.Game) (string, error) {
+	r, err := replay.New(g)
+	if err != nil {
+		return "", err
+	}
+	cfg := r.Config
+	var w bitWriter
+	w.write(codeVersion, 8)
+	w.write(uint(cfg.Width-1), 4)
+	w.write(uint(cfg.Height-1), 4)
+	sizes := cfg.Fleet.Sizes()
+	w.write(uint(len(sizes)), 4)
+	for _, l := range sizes {
+		if cfg.Fleet[l] > 255 {
+			return "", fmt.Errorf("%d ships of length %d do not fit in a game code", cfg.Fleet[l], l)
+		}
+		w.write(uint(l), 4)
+		w.write(uint(cfg.Fleet[l]), 8)
+	}
+	for _, s := range []string{cfg.Name, r.Header.Nick, r.Header.Opponent} {
+		if err := w.writeString(s); err != nil {
+			return "", err
+		}
+	}
+	var started uint
+	if !r.Header.Started.IsZero() {
+		started = uint(r.Header.Started.Unix())
+	}
+	w.write(started, 40)
+	w.writeBool(r.Header.Bot)
+	w.write(uint(index(codeOutcomes, r.Outcome)), 2)
+
+	w.writeFields(cfg, r.Header.Layout)
+	opp := oppLayout(r)
+	w.writeBool(len(opp) > 0)
+	if len(opp) > 0 {
+		w.writeFields(cfg, opp)
+	}
+
+	if len(r.Shots) >= 1<<10 {
+		return "", fmt.Errorf("%d shots do not fit in a game code", len(r.Shots))
+	}
+	w.write(uint(len(r.Shots)), 10)
+	if len(r.Shots) > 0 {
+		w.writeBool(r.Shots[0].Opponent)
+	}
+	fieldBits := uint(bits.Len(uint(cfg.Width*cfg.Height - 1)))
+	for _, s := range r.Shots {
+		w.write(uint(s.Coord.X*cfg.Height+s.Coord.Y), fieldBits)
+		w.write(uint(index(codeResults, s.Result)), 2)
+	}
+	return base64.RawURLEncoding.EncodeToString(w.buf), nil
+}
+
+// DecodeCode reads a game from its game code
+func DecodeCode(code string) (record.Game, error) {
+	data, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return record.Game{}, fmt.Errorf("%w: %v", ErrCode, err)
+	}
+	rd := bitReader{buf: data}
+	if v := rd.read(8); v != codeVersion {
+		return record.Game{}, fmt.Errorf("%w: unsupported version %d", ErrCode, v)
+	}
+	cfg := rules.Config{Width: int(rd.read(4)) + 1, Height: int(rd.read(4)) + 1, Fleet: rules.Fleet{}}
+	for n := rd.read(4); n > 0; n-- {
+		l := int(rd.read(4))
+		cfg.Fleet[l] = int(rd.read(8))
+	}
+	cfg.Name = rd.readString()
+	h := record.Header{Rules: cfg, Nick: rd.readString(), Opponent: rd.readString()}
+	if started := rd.read(40); started != 0 {
+		h.Started = time.Unix(int64(started), 0).UTC()
+	}
+	h.Bot = rd.readBool()
+	o := int(rd.read(2))
+	h.Layout = rd.readFields(cfg)
+	if rd.readBool() {
+		h.OppLayout = rd.readFields(cfg)
+	}
+
+	n := int(rd.read(10))
+	opponent := n > 0 && rd.readBool()
+	fieldBits := uint(bits.Len(uint(cfg.Width*cfg.Height - 1)))
+	shots := make([]shot, 0, n)
+	for i := 0; i < n; i++ {
+		f, res := int(rd.read(fieldBits)), int(rd.read(2))
+		if rd.err != nil || f >= cfg.Width*cfg.Height || res >= len(codeResults) {
+			return record.Game{}, fmt.Errorf("%w: shot %d", ErrCode, i+1)
+		}
+		s := shot{opponent: opponent, coord: coord.FromIndex(f/cfg.Height, f%cfg.Height), result: codeResults[res]}
+		shots = append(shots, s)
+		if !s.result.IsHit() {
+			opponent = !opponent
+		}
+	}
+	if rd.err != nil || o >= len(codeOutcomes) {
+		return record.Game{}, ErrCode
+	}
+	if err := cfg.Check(); err != nil {
+		return record.Game{}, fmt.Errorf("%w: %v", ErrCode, err)
+	}
+	for _, fields := range [][]string{h.Layout, h.OppLayout} {
+		if _, err := parseLayout(strings.Join(fields, " "), cfg); err != nil {
+			return record.Game{}, fmt.Errorf("%w: %v", ErrCode, err)
+		}
+	}
+	return build(h, shots, codeOutcomes[o])
+}
+
+func index[T comparable](values []T, v T) int {
+	for i, w := range values {
+		if w == v {
+			return i
+		}
+	}
+	return 0
+}
+
+// bitWriter appends values of any number of bits, the highest bit first
+type bitWriter struct {
+	buf []byte
+	n   uint
+}
+
+func (w *bitWriter) write(v uint, size uint) {
+	for i := size; i > 0; i-- {
+		if w.n%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		if v>>(i-1)&1 == 1 {
+			w.buf[len(w.buf)-1] |= 1 << (7 - w.n%8)
+		}
+		w.n++
+	}
+}
+
+func (w *bitWriter) writeBool(b bool) {
+	if b {
+		w.write(1, 1)
+	} else {
+		w.write(0, 1)
+	}
+}
+
+func (w *bitWriter) writeString(s string) error {
+	if len(s) > 255 {
+		return fmt.Errorf("%.20q... is too long for a game code", s)
+	}
+	w.write(uint(len(s)), 8)
+	for i := 0; i < len(s); i++ {
+		w.write(uint(s[i]), 8)
+	}
+	return nil
+}
+
+// writeFields writes one bit per field of the board, set for the given fields
+func (w *bitWriter) writeFields(cfg rules.Config, fields []string) {
+	set := map[coord.Coord]bool{}
+	for _, f := range fields {
+		if c, err := cfg.Parse(f); err == nil {
+			set[c] = true
+		}
+	}
+	for _, c := range cfg.Fields() {
+		w.writeBool(set[c])
+	}
+}
+
+// bitReader reads what bitWriter wrote, reading past the end sets err
+type bitReader struct {
+	buf []byte
+	n   uint
+	err error
+}
+
+func (r *bitReader) read(size uint) uint {
+	var v uint
+	for i := uint(0); i < size; i++ {
+		if r.n/8 >= uint(len(r.buf)) {
+			r.err = ErrCode
+			return 0
+		}
+		v = v<<1 | uint(r.buf[r.n/8]>>(7-r.n%8)&1)
+		r.n++
+	}
+	return v
+}
+
+func (r *bitReader) readBool() bool {
+	return r.read(1) == 1
+}
+
+func (r *bitReader) readString() string {
+	b := make([]byte, r.read(8))
+	for i := range b {
+		b[i] = byte(r.read(8))
+	}
+	return string(b)
+}
+
+func (r *bitReader) readFields(cfg rules.Config) []string {
+	var fields []string
+	for _, c := range cfg.Fields() {
+		if r.readBool() {
+			fields = append(fields, c.String())
+		}
+	}
+	return fields
+}
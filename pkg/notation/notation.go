@@ -0,0 +1,415 @@
+// Package notation writes whole games as compact text, similar to PGN for
+// chess, and as short game codes that fit in a chat message.
+//
+// A game is written as tags followed by the shots, turn by turn:
+//
+//	[Nick "me"]
+//	[Opp "wpbot"]
+//	[Variant "classic"]
+//	[Layout "A1-A4 C1-C3 ..."]
+//
+//	1. B5 M / C3 H C4 M 2. D7 H D8 S E2 M / ... 1-0
+//
+// Every round holds a turn of the player and, after the slash, a turn of the
+// opponent. A shot is a field and its result: M miss, H hit or S sunk. A game
+// the opponent opened starts with "1. .. /". The game ends with 1-0 if the
+// player won, 0-1 if the opponent won and * if it did not finish.
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/coord"
+	"warships/pkg/layout"
+	"warships/pkg/record"
+	"warships/pkg/replay"
+	"warships/pkg/rules"
+)
+
+// ErrSyntax is returned for text that is not written in the notation
+var ErrSyntax = errors.New("syntax error")
+
+// lineWidth is the width the shots are wrapped at
+const lineWidth = 79
+
+var results = map[api.ShotResult]string{
+	api.ShotMiss: "M",
+	api.ShotHit:  "H",
+	api.ShotSunk: "S",
+}
+
+var outcomes = map[api.GameOutcome]string{
+	api.OutcomeWin:  "1-0",
+	api.OutcomeLose: "0-1",
+}
+
+// shot is a shot read from a game, before the turn rule is checked
+type shot struct {
+	opponent bool
+	coord    coord.Coord
+	result   api.ShotResult
+}
+
+// Encode writes g in the notation
+func Encode(g record.Game) (string, error) {
+	r, err := replay.New(g)
+	if err != nil {
+		return "", err
+	}
+	h := r.Header
+	var b strings.Builder
+	tag := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "[%s %s]\n", name, strconv.Quote(value))
+		}
+	}
+	tag("Nick", h.Nick)
+	tag("Desc", h.Desc)
+	tag("Opp", h.Opponent)
+	tag("OppDesc", h.OppDesc)
+	tag("Bot", yesNo(h.Bot))
+	if !h.Started.IsZero() {
+		tag("Date", h.Started.UTC().Format(time.RFC3339))
+	}
+	tag("Variant", r.Config.Name)
+	if v, err := rules.Variant(r.Config.Name); err != nil || !sameRules(v, r.Config) {
+		tag("Board", fmt.Sprintf("%dx%d", r.Config.Width, r.Config.Height))
+		tag("Fleet", r.Config.Fleet.String())
+	}
+	tag("Layout", list(h.Layout))
+	tag("OppLayout", list(oppLayout(r)))
+	b.WriteString("\n")
+
+	var tokens []string
+	round := 0
+	for i, s := range r.Shots {
+		if i == 0 || r.Shots[i-1].Turn != s.Turn {
+			switch {
+			case !s.Opponent:
+				round++
+				tokens = append(tokens, fmt.Sprintf("%d.", round))
+			case i == 0:
+				round++
+				tokens = append(tokens, "1.", "..", "/")
+			default:
+				tokens = append(tokens, "/")
+			}
+		}
+		// a shot and its result stay on the same line
+		tokens = append(tokens, s.Coord.String()+" "+results[s.Result])
+	}
+	tokens = append(tokens, outcomeToken(r.Outcome))
+
+	line := 0
+	for i, t := range tokens {
+		if i > 0 && line+1+len(t) > lineWidth {
+			b.WriteString("\n")
+			line = 0
+		} else if i > 0 {
+			b.WriteString(" ")
+			line++
+		}
+		b.WriteString(t)
+		line += len(t)
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// Decode reads a game written in the notation
+func Decode(text string) (record.Game, error) {
+	tags, moves, err := splitTags(text)
+	if err != nil {
+		return record.Game{}, err
+	}
+	h := record.Header{
+		Nick:     tags["Nick"],
+		Desc:     tags["Desc"],
+		Opponent: tags["Opp"],
+		OppDesc:  tags["OppDesc"],
+		Bot:      tags["Bot"] == "yes",
+	}
+	if h.Rules, err = parseRules(tags["Variant"], tags["Board"], tags["Fleet"]); err != nil {
+		return record.Game{}, err
+	}
+	if d := tags["Date"]; d != "" {
+		if h.Started, err = time.Parse(time.RFC3339, d); err != nil {
+			return record.Game{}, fmt.Errorf("date: %w", err)
+		}
+	}
+	if h.Layout, err = parseLayout(tags["Layout"], h.Rules); err != nil {
+		return record.Game{}, fmt.Errorf("layout: %w", err)
+	}
+	if h.OppLayout, err = parseLayout(tags["OppLayout"], h.Rules); err != nil {
+		return record.Game{}, fmt.Errorf("opponent layout: %w", err)
+	}
+	shots, outcome, err := parseMoves(moves, h.Rules)
+	if err != nil {
+		return record.Game{}, err
+	}
+	return build(h, shots, outcome)
+}
+
+// Load reads a game from a recording, a file in the notation or a file
+// holding a game code, s may also be the game code itself
+func Load(s string) (record.Game, error) {
+	data, err := os.ReadFile(s)
+	if errors.Is(err, os.ErrNotExist) && isCode(s) {
+		g, cerr := DecodeCode(s)
+		if cerr != nil {
+			return record.Game{}, fmt.Errorf("%s is neither a file nor a game code", s)
+		}
+		return g, nil
+	}
+	if err != nil {
+		return record.Game{}, err
+	}
+	text := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(text, "{"):
+		return record.Read(strings.NewReader(text))
+	case isCode(text):
+		return DecodeCode(text)
+	}
+	return Decode(text)
+}
+
+// isCode reports whether s only holds characters of a game code
+func isCode(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// splitTags reads the tags in front of the shots
+func splitTags(text string) (map[string]string, string, error) {
+	tags := map[string]string{}
+	rest := strings.TrimSpace(text)
+	for strings.HasPrefix(rest, "[") {
+		end := closingBracket(rest)
+		if end < 0 {
+			return nil, "", fmt.Errorf("%w: unterminated tag %.20q", ErrSyntax, rest)
+		}
+		name, value, ok := strings.Cut(rest[1:end], " ")
+		if !ok {
+			return nil, "", fmt.Errorf("%w: tag %q has no value", ErrSyntax, rest[:end+1])
+		}
+		v, err := strconv.Unquote(strings.TrimSpace(value))
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: value of tag %s: %v", ErrSyntax, name, err)
+		}
+		tags[name] = v
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+	return tags, rest, nil
+}
+
+// closingBracket returns the index of the bracket closing the tag at the
+// start of s, brackets inside the quoted value do not count
+func closingBracket(s string) int {
+	quoted, escaped := false, false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = quoted
+		case r == '"':
+			quoted = !quoted
+		case r == ']' && !quoted:
+			return i
+		}
+	}
+	return -1
+}
+
+// parseMoves reads the shots and the outcome that ends them
+func parseMoves(text string, cfg rules.Config) ([]shot, api.GameOutcome, error) {
+	var shots []shot
+	tokens := strings.Fields(text)
+	round, opponent := 0, false
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t == "/":
+			if opponent || round == 0 {
+				return nil, "", fmt.Errorf("%w: unexpected / in round %d", ErrSyntax, round)
+			}
+			opponent = true
+		case t == "..":
+			if round != 1 || len(shots) > 0 {
+				return nil, "", fmt.Errorf("%w: .. only opens the first round", ErrSyntax)
+			}
+		case t == "*" || t == "1-0" || t == "0-1":
+			if i != len(tokens)-1 {
+				return nil, "", fmt.Errorf("%w: shots after the end of the game", ErrSyntax)
+			}
+			for o, token := range outcomes {
+				if token == t {
+					return shots, o, nil
+				}
+			}
+			return shots, api.OutcomeNone, nil
+		case strings.HasSuffix(t, "."):
+			n, err := strconv.Atoi(strings.TrimSuffix(t, "."))
+			if err != nil || n != round+1 {
+				return nil, "", fmt.Errorf("%w: expected round %d, got %q", ErrSyntax, round+1, t)
+			}
+			round, opponent = n, false
+		default:
+			if round == 0 {
+				return nil, "", fmt.Errorf("%w: shot %q before the first round", ErrSyntax, t)
+			}
+			c, err := cfg.Parse(t)
+			if err != nil {
+				return nil, "", fmt.Errorf("round %d: %w", round, err)
+			}
+			if i+1 == len(tokens) {
+				return nil, "", fmt.Errorf("%w: shot at %v has no result", ErrSyntax, c)
+			}
+			i++
+			result, ok := parseResult(tokens[i])
+			if !ok {
+				return nil, "", fmt.Errorf("%w: result %q of the shot at %v, expected M, H or S", ErrSyntax, tokens[i], c)
+			}
+			shots = append(shots, shot{opponent: opponent, coord: c, result: result})
+		}
+	}
+	return nil, "", fmt.Errorf("%w: the game does not end with 1-0, 0-1 or *", ErrSyntax)
+}
+
+func parseResult(s string) (api.ShotResult, bool) {
+	for r, token := range results {
+		if strings.EqualFold(token, s) {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// build checks the turn rule and turns the shots into the events of a recording
+func build(h record.Header, shots []shot, outcome api.GameOutcome) (record.Game, error) {
+	h.Type, h.Version = "header", record.Version
+	g := record.Game{Header: h}
+	if len(shots) > 0 {
+		g.Events = append(g.Events, record.Event{Type: record.EventTurn, ShouldFire: !shots[0].opponent})
+		opponent := shots[0].opponent
+		for i, s := range shots {
+			if s.opponent != opponent {
+				return record.Game{}, fmt.Errorf("shot %d at %v is out of turn, a miss passes the turn and a hit keeps it", i+1, s.coord)
+			}
+			if !s.result.IsHit() {
+				opponent = !opponent
+			}
+			e := record.Event{Type: record.EventShot, Coord: s.coord.String(), Result: s.result}
+			if s.opponent {
+				e.Type = record.EventOpponentShot
+			}
+			g.Events = append(g.Events, e)
+		}
+	}
+	if outcome != api.OutcomeNone {
+		g.Events = append(g.Events, record.Event{Type: record.EventEnd, Outcome: outcome})
+	}
+	return g, nil
+}
+
+// parseRules reads the variant, the board and the fleet default to the variant's
+func parseRules(variant, board, fleet string) (rules.Config, error) {
+	cfg := rules.Classic()
+	if variant != "" {
+		if v, err := rules.Variant(variant); err == nil {
+			cfg = v
+		} else if board == "" || fleet == "" {
+			return rules.Config{}, err
+		}
+		cfg.Name = variant
+	}
+	if board != "" {
+		w, hgt, ok := strings.Cut(board, "x")
+		width, err1 := strconv.Atoi(w)
+		height, err2 := strconv.Atoi(hgt)
+		if !ok || err1 != nil || err2 != nil {
+			return rules.Config{}, fmt.Errorf("%w: board %q, expected WxH", ErrSyntax, board)
+		}
+		cfg.Width, cfg.Height = width, height
+	}
+	if fleet != "" {
+		f := rules.Fleet{}
+		for _, part := range strings.Fields(fleet) {
+			l, n, ok := strings.Cut(part, "x")
+			length, err1 := strconv.Atoi(l)
+			count, err2 := strconv.Atoi(n)
+			if !ok || err1 != nil || err2 != nil {
+				return rules.Config{}, fmt.Errorf("%w: fleet %q, expected ships as LENGTHxCOUNT", ErrSyntax, fleet)
+			}
+			f[length] += count
+		}
+		cfg.Fleet = f
+	}
+	return cfg, cfg.Check()
+}
+
+func parseLayout(s string, cfg rules.Config) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	l, err := layout.ParseList(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return l.Strings(), nil
+}
+
+// oppLayout returns the opponent's fleet if it is known: from the recording,
+// or from the shots once the player sank every ship
+func oppLayout(r *replay.Replay) []string {
+	if len(r.Header.OppLayout) > 0 || r.Outcome != api.OutcomeWin {
+		return r.Header.OppLayout
+	}
+	var hits []coord.Coord
+	for _, s := range r.Shots {
+		if !s.Opponent && s.Result.IsHit() {
+			hits = append(hits, s.Coord)
+		}
+	}
+	if layout.Layout(hits).Validate(r.Config) != nil {
+		return nil
+	}
+	return coord.Strings(hits)
+}
+
+// list formats fields as a list of ships
+func list(fields []string) string {
+	cs, err := coord.ParseAll(fields)
+	if err != nil {
+		return ""
+	}
+	return layout.Layout(cs).List()
+}
+
+func sameRules(a, b rules.Config) bool {
+	return a.Width == b.Width && a.Height == b.Height && a.Fleet.String() == b.Fleet.String()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func outcomeToken(o api.GameOutcome) string {
+	if t, ok := outcomes[o]; ok {
+		return t
+	}
+	return "*"
+}
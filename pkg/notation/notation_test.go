@@ -0,0 +1,193 @@
+package notation
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/layout"
+	"warships/pkg/record"
+	"warships/pkg/rules"
+)
+
+func TestRoundTrip(t *testing.T) {
+	mini := rules.Config{Name: "mini", Width: 6, Height: 6, Fleet: rules.Fleet{3: 1, 2: 1, 1: 2}}
+	tests := []struct {
+		name     string
+		cfg      rules.Config
+		oppFirst bool
+		limit    int
+		// contains are parts of the notation written
+		contains []string
+	}{
+		{name: "classic", cfg: rules.Classic(), contains: []string{"1. ", "[Variant \"classic\"]"}},
+		{name: "opponent opened", cfg: rules.Classic(), oppFirst: true, limit: 15, contains: []string{"1. .. / ", " *\n"}},
+		{name: "opponent opened to the end", cfg: rules.Classic(), oppFirst: true, contains: []string{"1. .. / "}},
+		{name: "training", cfg: rules.Training(), contains: []string{"[Variant \"training\"]"}},
+		{name: "custom variant", cfg: mini, contains: []string{"[Variant \"mini\"]", "[Board \"6x6\"]", "[Fleet \"3x1 2x1 1x2\"]"}},
+		{name: "no shots", cfg: rules.Classic(), limit: -1, contains: []string{"\n*\n"}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := play(t, tt.cfg, int64(i), tt.oppFirst, tt.limit)
+
+			text, err := Encode(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(text, s) {
+					t.Errorf("%q not in\n%s", s, text)
+				}
+			}
+			got, err := Decode(text)
+			if err != nil {
+				t.Fatalf("%v in\n%s", err, text)
+			}
+			if !reflect.DeepEqual(normalize(got), normalize(g)) {
+				t.Errorf("notation: got %+v, want %+v", got, g)
+			}
+
+			code, err := EncodeCode(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err = DecodeCode(code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// codes leave out the descriptions
+			want := g
+			want.Header.Desc, want.Header.OppDesc = "", ""
+			if !reflect.DeepEqual(normalize(got), normalize(want)) {
+				t.Errorf("code: got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeOutOfTurn(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves string
+	}{
+		{"player fires after a miss", "1. A1 M A2 M *"},
+		{"opponent fires after the player hit", "1. A1 H / B1 M *"},
+		{"player fires after the opponent hit", "1. .. / A1 H 2. B1 M *"},
+		{"opponent fires after a miss", "1. .. / A1 M B1 M *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Decode("[Variant \"classic\"]\n\n" + tt.moves)
+			if err == nil || !strings.Contains(err.Error(), "out of turn") {
+				t.Errorf("got %+v, %v", g, err)
+			}
+		})
+	}
+}
+
+func TestDecodeTruncatedCode(t *testing.T) {
+	code, err := EncodeCode(play(t, rules.Classic(), 1, false, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < len(code); n++ {
+		if g, err := DecodeCode(code[:n]); !errors.Is(err, ErrCode) {
+			t.Fatalf("%d of %d characters: got %+v, %v", n, len(code), g, err)
+		}
+	}
+}
+
+func TestEncodeCodeFleetTooLarge(t *testing.T) {
+	g := record.Game{Header: record.Header{
+		Rules: rules.Config{Name: "swarm", Width: 10, Height: 10, Fleet: rules.Fleet{1: 256}},
+	}}
+	if code, err := EncodeCode(g); err == nil {
+		t.Errorf("got code %q", code)
+	}
+	g.Header.Rules.Fleet[1] = 255
+	if _, err := EncodeCode(g); err != nil {
+		t.Error(err)
+	}
+}
+
+// play plays a random game of cfg by the turn rule. It stops once a fleet is
+// sunk or after limit shots when limit is positive, a negative limit fires none.
+func play(t *testing.T, cfg rules.Config, seed int64, oppFirst bool, limit int) record.Game {
+	t.Helper()
+	rnd := rand.New(rand.NewSource(seed))
+	var boards [2]*rules.Board
+	var layouts [2][]string
+	for i := range boards {
+		l, err := layout.NewGenerator(cfg, rnd.Int63()).Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if boards[i], err = rules.NewBoard(cfg, l); err != nil {
+			t.Fatal(err)
+		}
+		layouts[i] = l.Strings()
+	}
+	// boards[0] is the opponent's, fired at by the player
+	var targets [2][]int
+	for i := range targets {
+		targets[i] = rnd.Perm(cfg.Width * cfg.Height)
+	}
+
+	var shots []shot
+	outcome := api.OutcomeNone
+	side := 0
+	if oppFirst {
+		side = 1
+	}
+	for limit >= 0 && (limit == 0 || len(shots) < limit) {
+		f := targets[side][0]
+		targets[side] = targets[side][1:]
+		c := cfg.Fields()[f]
+		res, err := boards[side].Fire(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shots = append(shots, shot{opponent: side == 1, coord: c, result: api.ShotResult(res)})
+		if boards[side].Over() {
+			outcome = api.OutcomeWin
+			if side == 1 {
+				outcome = api.OutcomeLose
+			}
+			break
+		}
+		if res == rules.Miss {
+			side = 1 - side
+		}
+	}
+
+	h := record.Header{
+		Nick:      "tester",
+		Desc:      "writes tests",
+		Opponent:  "wpbot",
+		OppDesc:   "WP bot",
+		Bot:       true,
+		Rules:     cfg,
+		Layout:    layouts[1],
+		OppLayout: layouts[0],
+		Started:   time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+	}
+	g, err := build(h, shots, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// normalize sorts the layouts, the forms order their fields differently
+func normalize(g record.Game) record.Game {
+	for _, l := range []*[]string{&g.Header.Layout, &g.Header.OppLayout} {
+		*l = append([]string(nil), *l...)
+		sort.Strings(*l)
+	}
+	return g
+}
@@ -0,0 +1,380 @@
+// Package record writes games to JSON lines files and reads them back. The
+// first line is the header, every following line an event.
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/rules"
+)
+
+// Version is the version of the file format written
+const Version = 1
+
+// Header describes the game and the player's side of it
+type Header struct {
+	Type     string       `json:"type"`
+	Version  int          `json:"version"`
+	Nick     string       `json:"nick"`
+	Desc     string       `json:"desc"`
+	Opponent string       `json:"opponent"`
+	OppDesc  string       `json:"opp_desc"`
+	Bot      bool         `json:"bot"`
+	Server   string       `json:"server,omitempty"`
+	Rules    rules.Config `json:"rules"`
+	// Layout holds the player's ship coordinates
+	Layout []string `json:"layout"`
+	// OppLayout holds the opponent's ship coordinates when they are known
+	OppLayout []string  `json:"opp_layout,omitempty"`
+	Started   time.Time `json:"started"`
+}
+
+// EventType tells what an event records
+type EventType string
+
+const (
+	// EventShot is a shot of the player with the result reported by the server
+	EventShot EventType = "shot"
+	// EventOpponentShot is a shot of the opponent at the player's board
+	EventOpponentShot EventType = "opponent_shot"
+	// EventTurn records whether the player may fire
+	EventTurn EventType = "turn"
+	// EventTimer is the value of the turn timer
+	EventTimer EventType = "timer"
+	// EventPhase is a change of the game status
+	EventPhase EventType = "phase"
+	// EventEnd closes the game with its outcome
+	EventEnd EventType = "end"
+)
+
+// Event is a single line after the header, only the fields of its type are set
+type Event struct {
+	Type       EventType       `json:"type"`
+	Time       time.Time       `json:"time"`
+	Coord      string          `json:"coord,omitempty"`
+	Result     api.ShotResult  `json:"result,omitempty"`
+	ShouldFire bool            `json:"should_fire,omitempty"`
+	Timer      int             `json:"timer,omitempty"`
+	Phase      api.GamePhase   `json:"phase,omitempty"`
+	Outcome    api.GameOutcome `json:"outcome,omitempty"`
+}
+
+// Recorder writes a game as it is played. Events recorded before the header
+// is known are held back so that the header always comes first. It is safe
+// for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	w       io.Writer
+	enc     *json.Encoder
+	header  Header
+	written bool
+	pending []Event
+	err     error
+	// oppShots holds the opponent shots recorded, a resumed game reports them all again
+	oppShots map[string]bool
+	// Now returns the time of events, defaults to time.Now
+	Now func() time.Time
+}
+
+// New returns a recorder writing to w
+func New(w io.Writer, h Header) *Recorder {
+	return &Recorder{w: w, enc: json.NewEncoder(w), header: h, Now: time.Now, oppShots: map[string]bool{}}
+}
+
+// Create opens the file at path to record a game. A file holding a game
+// already, e.g. one resumed after a restart, is appended to, a line cut off
+// by a crash is dropped first.
+func Create(path string, h Header) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	prev, err := Load(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil && !errors.Is(err, io.ErrUnexpectedEOF):
+		return nil, err
+	default:
+		if err := fixTail(path, err != nil); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	r := New(f, h)
+	if prev.Header.Type != "" {
+		r.header = prev.Header
+		r.written = true
+		for _, e := range prev.Shots(true) {
+			r.oppShots[e.Coord] = true
+		}
+	}
+	return r, nil
+}
+
+// fixTail prepares the file at path for appending: the last line is dropped
+// if it was cut off, otherwise it is ended with a newline if it lacks one
+func fixTail(path string, cut bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return err
+	}
+	if cut {
+		data = bytes.TrimRight(data, "\n")
+		return os.Truncate(path, int64(bytes.LastIndexByte(data, '\n')+1))
+	}
+	if data[len(data)-1] == '\n' {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString("\n")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// FileName returns a file name for a game of nick started at t
+func FileName(nick string, t time.Time) string {
+	safe := []rune(nick)
+	for i, c := range safe {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			safe[i] = '_'
+		}
+	}
+	return fmt.Sprintf("%s-%s.jsonl", t.Format("20060102-150405"), string(safe))
+}
+
+// SetPlayers completes the header once the opponent is known and writes it
+// together with the events held back
+func (r *Recorder) SetPlayers(nick, desc, opponent, oppDesc string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if nick != "" {
+		r.header.Nick = nick
+	}
+	if desc != "" {
+		r.header.Desc = desc
+	}
+	r.header.Opponent = opponent
+	r.header.OppDesc = oppDesc
+	return r.flush()
+}
+
+// Record writes e, stamping it with the current time unless it has one
+func (r *Recorder) Record(e Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e.Time.IsZero() {
+		e.Time = r.Now()
+	}
+	if !r.written {
+		r.pending = append(r.pending, e)
+		return nil
+	}
+	return r.write(e)
+}
+
+// Shot records a shot of the player
+func (r *Recorder) Shot(coord string, result api.FireResult) error {
+	return r.Record(Event{Type: EventShot, Coord: coord, Result: result.Result})
+}
+
+// OpponentShot records a shot of the opponent, shots recorded already are skipped
+func (r *Recorder) OpponentShot(coord string, result api.ShotResult) error {
+	r.mu.Lock()
+	seen := r.oppShots[coord]
+	r.oppShots[coord] = true
+	r.mu.Unlock()
+	if seen {
+		return nil
+	}
+	return r.Record(Event{Type: EventOpponentShot, Coord: coord, Result: result})
+}
+
+// Turn records whether the player may fire
+func (r *Recorder) Turn(shouldFire bool) error {
+	return r.Record(Event{Type: EventTurn, ShouldFire: shouldFire})
+}
+
+// Timer records the turn timer
+func (r *Recorder) Timer(timer int) error {
+	return r.Record(Event{Type: EventTimer, Timer: timer})
+}
+
+// Phase records a change of the game status
+func (r *Recorder) Phase(phase api.GamePhase) error {
+	return r.Record(Event{Type: EventPhase, Phase: phase})
+}
+
+// End records the outcome of the game
+func (r *Recorder) End(outcome api.GameOutcome) error {
+	return r.Record(Event{Type: EventEnd, Outcome: outcome})
+}
+
+// Close writes whatever is held back and closes the underlying writer if it is a closer
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.flush()
+	if c, ok := r.w.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// flush writes the header and the pending events, r.mu must be held
+func (r *Recorder) flush() error {
+	if r.written {
+		return r.err
+	}
+	r.written = true
+	r.header.Type = "header"
+	r.header.Version = Version
+	if err := r.write(r.header); err != nil {
+		return err
+	}
+	for _, e := range r.pending {
+		if err := r.write(e); err != nil {
+			return err
+		}
+	}
+	r.pending = nil
+	return nil
+}
+
+// write encodes one line, after the first failure nothing more is written
+func (r *Recorder) write(v any) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.err = r.enc.Encode(v)
+	return r.err
+}
+
+// Game is a recorded game
+type Game struct {
+	Header Header
+	Events []Event
+}
+
+// Shots returns the shots of the player, opponent is set for the shots of the opponent instead
+func (g Game) Shots(opponent bool) []Event {
+	want := EventShot
+	if opponent {
+		want = EventOpponentShot
+	}
+	var shots []Event
+	for _, e := range g.Events {
+		if e.Type == want {
+			shots = append(shots, e)
+		}
+	}
+	return shots
+}
+
+// Outcome returns the outcome of the game, none if it did not end
+func (g Game) Outcome() api.GameOutcome {
+	for i := len(g.Events) - 1; i >= 0; i-- {
+		if g.Events[i].Type == EventEnd {
+			return g.Events[i].Outcome
+		}
+	}
+	return api.OutcomeNone
+}
+
+// Read reads a recorded game. A malformed last line is taken for a write cut
+// off by a crash: the game read so far is returned with an error wrapping
+// io.ErrUnexpectedEOF, as it is for an empty recording.
+func Read(r io.Reader) (Game, error) {
+	var g Game
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	line, bad := 0, 0
+	var badErr error
+	for sc.Scan() {
+		line++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		if badErr != nil {
+			return g, fmt.Errorf("line %d: %w", bad, badErr)
+		}
+		if line == 1 {
+			if err := json.Unmarshal(sc.Bytes(), &g.Header); err != nil {
+				g.Header = Header{}
+				bad, badErr = line, err
+				continue
+			}
+			if g.Header.Type != "header" {
+				return g, errors.New("line 1: not a game header")
+			}
+			if g.Header.Version > Version {
+				return g, fmt.Errorf("unsupported version %d", g.Header.Version)
+			}
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			bad, badErr = line, err
+			continue
+		}
+		g.Events = append(g.Events, e)
+	}
+	if err := sc.Err(); err != nil {
+		return g, err
+	}
+	if badErr != nil {
+		return g, fmt.Errorf("line %d is cut off: %w", bad, io.ErrUnexpectedEOF)
+	}
+	if line == 0 {
+		return g, io.ErrUnexpectedEOF
+	}
+	return g, nil
+}
+
+// Write writes g as a recording
+func Write(w io.Writer, g Game) error {
+	enc := json.NewEncoder(w)
+	h := g.Header
+	h.Type = "header"
+	h.Version = Version
+	if err := enc.Encode(h); err != nil {
+		return err
+	}
+	for _, e := range g.Events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads the recorded game in the file at path
+func Load(path string) (Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Game{}, err
+	}
+	defer f.Close()
+	g, err := Read(f)
+	if err != nil {
+		return g, fmt.Errorf("%s: %w", path, err)
+	}
+	return g, nil
+}
@@ -0,0 +1,125 @@
+package record
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/rules"
+)
+
+const (
+	header = `{"type":"header","version":1,"nick":"tester","desc":"","opponent":"wpbot","opp_desc":"","bot":true,"rules":{"name":"classic","width":10,"height":10,"fleet":{"1":4,"2":3,"3":2,"4":1}},"layout":["A1"],"started":"2024-05-01T12:00:00Z"}`
+	shot   = `{"type":"shot","time":"2024-05-01T12:00:05Z","coord":"B2","result":"miss"}`
+	end    = `{"type":"end","time":"2024-05-01T12:10:00Z","outcome":"win"}`
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		events    int
+		truncated bool
+		wantErr   bool
+	}{
+		{name: "game", text: header + "\n" + shot + "\n" + end + "\n", events: 2},
+		{name: "blank lines", text: header + "\n\n" + shot + "\n\n", events: 1},
+		{name: "no trailing newline", text: header + "\n" + shot, events: 1},
+		{name: "cut off event", text: header + "\n" + shot + "\n" + end[:20], events: 1, truncated: true},
+		{name: "cut off event with newline", text: header + "\n" + shot + "\n" + end[:20] + "\n\n", events: 1, truncated: true},
+		{name: "cut off header", text: header[:30], truncated: true},
+		{name: "empty", text: "", truncated: true},
+		{name: "malformed event", text: header + "\n" + end[:20] + "\n" + shot + "\n", wantErr: true},
+		{name: "malformed header", text: header[:30] + "\n" + shot + "\n", wantErr: true},
+		{name: "not a header", text: shot + "\n" + end + "\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Read(strings.NewReader(tt.text))
+			switch {
+			case tt.truncated:
+				if !errors.Is(err, io.ErrUnexpectedEOF) {
+					t.Fatalf("got %v, want a truncated game", err)
+				}
+			case tt.wantErr:
+				if err == nil || errors.Is(err, io.ErrUnexpectedEOF) {
+					t.Fatalf("got %v, want a malformed game", err)
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if len(g.Events) != tt.events {
+				t.Errorf("got %d event(s), want %d", len(g.Events), tt.events)
+			}
+		})
+	}
+}
+
+func TestCreateAfterCrash(t *testing.T) {
+	tests := []struct {
+		name string
+		// tail is what the crash left at the end of the file
+		tail string
+		// resumed is set if the game read so far survives
+		resumed bool
+	}{
+		{"complete", "", true},
+		{"no trailing newline", end, true},
+		{"cut off event", end[:20], true},
+		{"cut off event with newline", end[:20] + "\n", true},
+		{"cut off header", header[:30], false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "game.jsonl")
+			text := tt.tail
+			if tt.resumed {
+				text = header + "\n" + shot + "\n" + tt.tail
+			}
+			if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := Create(path, Header{Nick: "tester", Rules: rules.Classic()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Now = func() time.Time { return time.Date(2024, 5, 1, 12, 5, 0, 0, time.UTC) }
+			if err := r.SetPlayers("", "", "wpbot", ""); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Shot("C3", api.FireResult{Result: api.ShotHit}); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.End(api.OutcomeWin); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			g, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var coords []string
+			for _, e := range g.Shots(false) {
+				coords = append(coords, e.Coord)
+			}
+			want := []string{"C3"}
+			if tt.resumed {
+				want = []string{"B2", "C3"}
+			}
+			if !reflect.DeepEqual(coords, want) || g.Outcome() != api.OutcomeWin || g.Header.Opponent != "wpbot" {
+				t.Errorf("got shots %v, outcome %q and opponent %q", coords, g.Outcome(), g.Header.Opponent)
+			}
+		})
+	}
+}
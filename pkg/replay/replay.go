@@ -0,0 +1,203 @@
+// Package replay steps through a recorded game shot by shot, rebuilding both
+// boards the way the player saw them.
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/coord"
+	"warships/pkg/record"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+	"warships/pkg/strategy"
+)
+
+// ErrNoTurn is returned when jumping to a turn the game did not have
+var ErrNoTurn = errors.New("no such turn")
+
+// Shot is a single shot of the game
+type Shot struct {
+	// Opponent is set for the shots at the player's board
+	Opponent bool
+	Coord    coord.Coord
+	Result   api.ShotResult
+	Time     time.Time
+	// Turn counts from 1, a turn lasts as long as the same side fires
+	Turn int
+}
+
+// Replay is a recorded game ready to be stepped through
+type Replay struct {
+	Header  record.Header
+	Config  rules.Config
+	Shots   []Shot
+	Outcome api.GameOutcome
+	ships   map[coord.Coord]bool
+	// turns holds the index of the first shot of every turn
+	turns []int
+}
+
+// New prepares the replay of g
+func New(g record.Game) (*Replay, error) {
+	cfg := g.Header.Rules
+	if cfg.Width == 0 {
+		// recordings without rules were played on the server's board
+		cfg = rules.Classic()
+	}
+	if err := cfg.Check(); err != nil {
+		return nil, err
+	}
+	r := &Replay{Header: g.Header, Config: cfg, Outcome: g.Outcome(), ships: map[coord.Coord]bool{}}
+	for _, s := range g.Header.Layout {
+		c, err := cfg.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("layout: %w", err)
+		}
+		r.ships[c] = true
+	}
+	var shots [2][]Shot
+	first := -1
+	for _, e := range g.Events {
+		if e.Type == record.EventTurn && first < 0 {
+			first = 1
+			if e.ShouldFire {
+				first = 0
+			}
+		}
+		if e.Type != record.EventShot && e.Type != record.EventOpponentShot {
+			continue
+		}
+		c, err := cfg.Parse(e.Coord)
+		if err != nil {
+			return nil, fmt.Errorf("shot at %s: %w", e.Time.Format(time.TimeOnly), err)
+		}
+		shot := Shot{Opponent: e.Type == record.EventOpponentShot, Coord: c, Result: e.Result, Time: e.Time}
+		side := 0
+		if shot.Opponent {
+			side = 1
+			if shot.Result == "" {
+				// the player board could not be loaded, the layout tells the result
+				shot.Result = api.ShotMiss
+				if r.ships[c] {
+					shot.Result = api.ShotHit
+				}
+			}
+		}
+		if first < 0 {
+			first = side
+		}
+		shots[side] = append(shots[side], shot)
+	}
+	r.order(shots, first)
+	return r, nil
+}
+
+// order merges the shots of both sides by the turn rule: a miss passes the
+// turn, a hit keeps it. The opponent's shots are only noticed when the
+// status is polled, so their recorded order cannot be trusted.
+func (r *Replay) order(shots [2][]Shot, side int) {
+	if side < 0 {
+		return
+	}
+	for len(shots[0])+len(shots[1]) > 0 {
+		if len(shots[side]) == 0 {
+			side = 1 - side
+		}
+		shot := shots[side][0]
+		shots[side] = shots[side][1:]
+		if n := len(r.Shots); n == 0 || r.Shots[n-1].Opponent != shot.Opponent {
+			r.turns = append(r.turns, n)
+		}
+		shot.Turn = len(r.turns)
+		r.Shots = append(r.Shots, shot)
+		if !shot.Result.IsHit() {
+			side = 1 - side
+		}
+	}
+}
+
+// Len returns the number of shots, the positions of the replay go from 0 to Len
+func (r *Replay) Len() int {
+	return len(r.Shots)
+}
+
+// Turns returns the number of turns
+func (r *Replay) Turns() int {
+	return len(r.turns)
+}
+
+// TurnStart returns the position before the first shot of turn n
+func (r *Replay) TurnStart(n int) (int, error) {
+	if n < 1 || n > len(r.turns) {
+		return 0, fmt.Errorf("turn %d: %w, the game had %d", n, ErrNoTurn, len(r.turns))
+	}
+	return r.turns[n-1], nil
+}
+
+// Position is the game after a number of shots
+type Position struct {
+	// Step is the number of shots fired so far
+	Step int
+	// PlayerBoard holds the player's fleet and the opponent's shots
+	PlayerBoard state.Grid
+	// OppBoard is what the player knew of the opponent board
+	OppBoard state.Grid
+	// Remaining is the opponent fleet still afloat
+	Remaining rules.Fleet
+	// Shots and Hits count the player's shots
+	Shots, Hits int
+	// Last is the shot that led to the position, Next the one fired from it
+	Last, Next *Shot
+}
+
+// At returns the position after step shots, step is clamped to the game
+func (r *Replay) At(step int) Position {
+	if step < 0 {
+		step = 0
+	}
+	if step > len(r.Shots) {
+		step = len(r.Shots)
+	}
+	p := Position{Step: step, PlayerBoard: state.NewGrid(r.Config.Width, r.Config.Height)}
+	for c := range r.ships {
+		p.PlayerBoard[c.X][c.Y] = state.Ship
+	}
+	var known []strategy.Shot
+	for _, s := range r.Shots[:step] {
+		if s.Opponent {
+			cell := state.Miss
+			if s.Result.IsHit() {
+				cell = state.Hit
+			}
+			p.PlayerBoard[s.Coord.X][s.Coord.Y] = cell
+			continue
+		}
+		p.Shots++
+		if s.Result.IsHit() {
+			p.Hits++
+		}
+		known = append(known, strategy.Shot{Coord: s.Coord, Result: rules.Result(s.Result)})
+	}
+	p.OppBoard, p.Remaining = strategy.Board(r.Config, known)
+	if step > 0 {
+		p.Last = &r.Shots[step-1]
+	}
+	if step < len(r.Shots) {
+		p.Next = &r.Shots[step]
+	}
+	return p
+}
+
+// Turn returns the turn of the position, the one of the next shot or the last
+// turn once the game is over
+func (p Position) Turn() int {
+	switch {
+	case p.Next != nil:
+		return p.Next.Turn
+	case p.Last != nil:
+		return p.Last.Turn
+	}
+	return 0
+}
@@ -0,0 +1,158 @@
+package rules
+
+import (
+	"errors"
+	"warships/pkg/coord"
+)
+
+// Result is the outcome of a shot, it uses the server notation
+type Result string
+
+const (
+	Miss Result = "miss"
+	Hit  Result = "hit"
+	Sunk Result = "sunk"
+)
+
+// ErrAlreadyShot is returned when a field is fired at twice
+var ErrAlreadyShot = errors.New("field was already shot")
+
+// Board is a validated layout together with the shots fired at it
+type Board struct {
+	cfg    Config
+	ships  []Ship
+	shipAt map[coord.Coord]int
+	left   []int
+	shots  map[coord.Coord]Result
+	order  []coord.Coord
+}
+
+// NewBoard validates cells against cfg and returns a board without shots
+func NewBoard(cfg Config, cells []coord.Coord) (*Board, error) {
+	ships, err := Validate(cfg, cells)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Fleet = cfg.Fleet.Clone()
+	b := &Board{
+		cfg:    cfg,
+		ships:  ships,
+		shipAt: map[coord.Coord]int{},
+		left:   make([]int, len(ships)),
+		shots:  map[coord.Coord]Result{},
+	}
+	for i, ship := range ships {
+		for _, c := range ship {
+			b.shipAt[c] = i
+		}
+		b.left[i] = len(ship)
+	}
+	return b, nil
+}
+
+// Fire resolves a shot at c. A repeated shot returns the first result
+// together with ErrAlreadyShot and changes nothing.
+func (b *Board) Fire(c coord.Coord) (Result, error) {
+	if !b.cfg.Contains(c) {
+		return "", ErrOffBoard
+	}
+	if r, ok := b.shots[c]; ok {
+		return r, ErrAlreadyShot
+	}
+	r := Miss
+	if i, ok := b.shipAt[c]; ok {
+		b.left[i]--
+		r = Hit
+		if b.left[i] == 0 {
+			r = Sunk
+		}
+	}
+	b.shots[c] = r
+	b.order = append(b.order, c)
+	return r, nil
+}
+
+// Over reports whether the whole fleet is sunk
+func (b *Board) Over() bool {
+	for _, left := range b.left {
+		if left > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Result returns the result of the shot at c, if there was one
+func (b *Board) Result(c coord.Coord) (Result, bool) {
+	r, ok := b.shots[c]
+	return r, ok
+}
+
+// Shots returns the fields fired at, in order
+func (b *Board) Shots() []coord.Coord {
+	return append([]coord.Coord(nil), b.order...)
+}
+
+// Config returns the variant the layout was validated against
+func (b *Board) Config() Config {
+	cfg := b.cfg
+	cfg.Fleet = cfg.Fleet.Clone()
+	return cfg
+}
+
+// Ships returns the ships of the layout, longest first
+func (b *Board) Ships() []Ship {
+	return append([]Ship(nil), b.ships...)
+}
+
+// ShipAt returns the ship occupying c
+func (b *Board) ShipAt(c coord.Coord) (Ship, bool) {
+	i, ok := b.shipAt[c]
+	if !ok {
+		return nil, false
+	}
+	return b.ships[i], true
+}
+
+// Cells returns every field occupied by a ship, in board order
+func (b *Board) Cells() []coord.Coord {
+	cells := make([]coord.Coord, 0, len(b.shipAt))
+	for c := range b.shipAt {
+		cells = append(cells, c)
+	}
+	sortCoords(cells)
+	return cells
+}
+
+// Remaining returns the ships not sunk yet
+func (b *Board) Remaining() Fleet {
+	f := Fleet{}
+	for i, ship := range b.ships {
+		if b.left[i] > 0 {
+			f[len(ship)]++
+		}
+	}
+	return f
+}
+
+// SunkShip returns the ship that a sinking shot at c destroyed, given the
+// fields known to be hit. It is meant for the tracking board of the opponent,
+// whose layout is unknown.
+func SunkShip(hits map[coord.Coord]bool, c coord.Coord) Ship {
+	set := map[coord.Coord]bool{c: true}
+	stack := []coord.Coord{c}
+	ship := Ship{}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		ship = append(ship, cur)
+		for _, n := range cur.Neighbours(coord.Size, coord.Size) {
+			if hits[n] && !set[n] {
+				set[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	sortCoords(ship)
+	return ship
+}
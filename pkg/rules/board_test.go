@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"warships/pkg/coord"
+)
+
+func TestFire(t *testing.T) {
+	b, err := NewBoard(mini, coords("A1", "A2", "A3", "C1", "D1", "E5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the shots are fired in order at the same board
+	tests := []struct {
+		coord   string
+		want    Result
+		wantErr error
+		over    bool
+	}{
+		{"B5", Miss, nil, false},
+		{"A2", Hit, nil, false},
+		{"A2", Hit, ErrAlreadyShot, false},
+		{"B5", Miss, ErrAlreadyShot, false},
+		{"F1", "", ErrOffBoard, false},
+		{"A1", Hit, nil, false},
+		{"A3", Sunk, nil, false},
+		{"A3", Sunk, ErrAlreadyShot, false},
+		{"E5", Sunk, nil, false},
+		{"C1", Hit, nil, false},
+		{"D1", Sunk, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := b.Fire(coord.MustParse(tt.coord))
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got %q, %v, want %q, %v", tt.coord, got, err, tt.want, tt.wantErr)
+		}
+		if b.Over() != tt.over {
+			t.Errorf("%s: over is %v, want %v", tt.coord, b.Over(), tt.over)
+		}
+	}
+	if want := coords("B5", "A2", "A1", "A3", "E5", "C1", "D1"); !reflect.DeepEqual(b.Shots(), want) {
+		t.Errorf("got shots %v, want %v", b.Shots(), want)
+	}
+	if len(b.Remaining()) != 0 {
+		t.Errorf("got remaining %v", b.Remaining())
+	}
+}
+
+func TestSunkShip(t *testing.T) {
+	tests := []struct {
+		name string
+		hits []string
+		c    string
+		want string
+	}{
+		{"single", nil, "E5", "E5"},
+		{"vertical", []string{"B2", "B3", "B4"}, "B5", "B2 B3 B4 B5"},
+		{"horizontal", []string{"C1", "E1"}, "D1", "C1 D1 E1"},
+		{"other ships apart", []string{"A1", "A2", "C2", "D2"}, "A3", "A1 A2 A3"},
+		{"diagonal hits ignored", []string{"B4", "D4"}, "C5", "C5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := map[coord.Coord]bool{}
+			for _, c := range coords(tt.hits...) {
+				hits[c] = true
+			}
+			want := ships(tt.want)[0]
+			if got := SunkShip(hits, coord.MustParse(tt.c)); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
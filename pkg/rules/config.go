@@ -0,0 +1,98 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"warships/pkg/coord"
+)
+
+// Config is a game variant: the board dimensions and the fleet
+type Config struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Fleet  Fleet  `json:"fleet"`
+}
+
+// Classic is the variant played by the server, the classic fleet on a 10×10 board
+func Classic() Config {
+	return Config{Name: "classic", Width: coord.Size, Height: coord.Size, Fleet: ClassicFleet()}
+}
+
+// Hasbro is the five ship fleet of the board game on a 10×10 board
+func Hasbro() Config {
+	return Config{Name: "hasbro", Width: coord.Size, Height: coord.Size, Fleet: Fleet{5: 1, 4: 1, 3: 2, 2: 1}}
+}
+
+// Training is a short game on an 8×8 board
+func Training() Config {
+	return Config{Name: "training", Width: 8, Height: 8, Fleet: Fleet{3: 1, 2: 2, 1: 3}}
+}
+
+var variants = map[string]func() Config{
+	"classic":  Classic,
+	"hasbro":   Hasbro,
+	"training": Training,
+}
+
+// Variant returns the variant called name
+func Variant(name string) (Config, error) {
+	v, ok := variants[name]
+	if !ok {
+		return Config{}, fmt.Errorf("unknown variant %q, known variants: %v", name, Variants())
+	}
+	return v(), nil
+}
+
+// Variants returns the names of the known variants
+func Variants() []string {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check reports a configuration that cannot be played: a board larger than
+// the client can draw, or a fleet that does not fit on it
+func (c Config) Check() error {
+	if c.Width < 1 || c.Height < 1 || c.Width > coord.Size || c.Height > coord.Size {
+		return fmt.Errorf("board must be between 1x1 and %dx%d, got %dx%d", coord.Size, coord.Size, c.Width, c.Height)
+	}
+	if c.Fleet.Ships() == 0 {
+		return fmt.Errorf("fleet is empty")
+	}
+	for l, n := range c.Fleet {
+		if l < 1 || n < 0 || (n > 0 && l > c.Width && l > c.Height) {
+			return fmt.Errorf("ship of length %d does not fit on a %dx%d board", l, c.Width, c.Height)
+		}
+	}
+	return nil
+}
+
+// Contains reports whether p lies on the board
+func (c Config) Contains(p coord.Coord) bool {
+	return p.In(c.Width, c.Height)
+}
+
+// Parse parses a coordinate on the board
+func (c Config) Parse(s string) (coord.Coord, error) {
+	return coord.ParseIn(s, c.Width, c.Height)
+}
+
+// Fields returns every field of the board, column by column
+func (c Config) Fields() []coord.Coord {
+	fields := make([]coord.Coord, 0, c.Width*c.Height)
+	for x := 0; x < c.Width; x++ {
+		for y := 0; y < c.Height; y++ {
+			fields = append(fields, coord.FromIndex(x, y))
+		}
+	}
+	return fields
+}
+
+// String describes the variant, e.g. classic 10x10 4x1 3x2 2x3 1x4
+func (c Config) String() string {
+	return fmt.Sprintf("%s %dx%d %v", c.Name, c.Width, c.Height, c.Fleet)
+}
@@ -0,0 +1,77 @@
+// Package rules implements the game rules independently of the GUI and the
+// HTTP client: the fleet, layout validation and shot resolution.
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fleet maps a ship length to the number of ships of that length
+type Fleet map[int]int
+
+// ClassicFleet returns the fleet used by the server: one four-master, two
+// three-masters, three two-masters and four single-masters
+func ClassicFleet() Fleet {
+	return Fleet{4: 1, 3: 2, 2: 3, 1: 4}
+}
+
+// Lengths returns the length of every ship, longest first
+func (f Fleet) Lengths() []int {
+	var lengths []int
+	for _, l := range f.Sizes() {
+		for i := 0; i < f[l]; i++ {
+			lengths = append(lengths, l)
+		}
+	}
+	return lengths
+}
+
+// Ships returns the number of ships
+func (f Fleet) Ships() int {
+	n := 0
+	for _, c := range f {
+		n += c
+	}
+	return n
+}
+
+// Cells returns the number of fields covered by the whole fleet
+func (f Fleet) Cells() int {
+	n := 0
+	for l, c := range f {
+		n += l * c
+	}
+	return n
+}
+
+// Clone returns a copy of f
+func (f Fleet) Clone() Fleet {
+	c := make(Fleet, len(f))
+	for l, n := range f {
+		c[l] = n
+	}
+	return c
+}
+
+// String formats f longest ship first, e.g. 4x1 3x2 2x3 1x4
+func (f Fleet) String() string {
+	var parts []string
+	for _, l := range f.Sizes() {
+		if f[l] > 0 {
+			parts = append(parts, fmt.Sprintf("%dx%d", l, f[l]))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Sizes returns the distinct ship lengths of f, longest first
+func (f Fleet) Sizes() []int {
+	sizes := make([]int, 0, len(f))
+	for l := range f {
+		sizes = append(sizes, l)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
@@ -0,0 +1,212 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"warships/pkg/coord"
+)
+
+var (
+	ErrOffBoard    = errors.New("field is off the board")
+	ErrDuplicate   = errors.New("field is used twice")
+	ErrNotStraight = errors.New("ships must be straight lines")
+	ErrTouching    = errors.New("ships must not touch")
+)
+
+// FleetError is returned for a layout whose ships do not match the fleet
+type FleetError struct {
+	Length int
+	Want   int
+	Got    int
+}
+
+func (e *FleetError) Error() string {
+	return fmt.Sprintf("expected %d ship(s) of length %d, got %d", e.Want, e.Length, e.Got)
+}
+
+// Ship is the list of fields occupied by a single ship
+type Ship []coord.Coord
+
+// Contains reports whether the ship occupies c
+func (s Ship) Contains(c coord.Coord) bool {
+	for _, sc := range s {
+		if sc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Border returns the fields touching the ship, edges and corners, on a w×h board
+func (s Ship) Border(w, h int) []coord.Coord {
+	seen := map[coord.Coord]bool{}
+	var border []coord.Coord
+	for _, c := range s {
+		for _, n := range c.Around(w, h) {
+			if !seen[n] && !s.Contains(n) {
+				seen[n] = true
+				border = append(border, n)
+			}
+		}
+	}
+	return border
+}
+
+// Validate checks that cells form the fleet of cfg on its board: every ship
+// is a straight line, no two ships touch, not even diagonally, and the ship
+// counts match. The order of cells does not matter. It returns the ships.
+func Validate(cfg Config, cells []coord.Coord) ([]Ship, error) {
+	f := cfg.Fleet
+	set, err := cellSet(cfg, cells)
+	if err != nil {
+		return nil, err
+	}
+
+	ships := Ships(set)
+	counts := map[int]int{}
+	for _, ship := range ships {
+		if !straight(ship) {
+			return nil, ErrNotStraight
+		}
+		counts[len(ship)]++
+	}
+	if err := touching(ships); err != nil {
+		return nil, err
+	}
+	for _, l := range f.Clone().withLengths(counts).Sizes() {
+		if counts[l] != f[l] {
+			return nil, &FleetError{Length: l, Want: f[l], Got: counts[l]}
+		}
+	}
+	return ships, nil
+}
+
+// CheckShip checks that ship is a single straight ship which neither overlaps
+// nor touches the fields in placed, e.g. while a layout is built ship by ship
+func CheckShip(cfg Config, ship []coord.Coord, placed []coord.Coord) error {
+	set, err := cellSet(cfg, ship)
+	if err != nil {
+		return err
+	}
+	ships := Ships(set)
+	if len(ships) != 1 || !straight(ships[0]) {
+		return ErrNotStraight
+	}
+	taken := map[coord.Coord]bool{}
+	for _, c := range placed {
+		taken[c] = true
+	}
+	for _, c := range ship {
+		if taken[c] {
+			return ErrDuplicate
+		}
+		for _, n := range c.Around(cfg.Width, cfg.Height) {
+			if taken[n] {
+				return ErrTouching
+			}
+		}
+	}
+	return nil
+}
+
+// Ships groups the fields of set into ships made of orthogonally connected
+// fields. Ships are returned longest first, the fields of each ship in order.
+func Ships(set map[coord.Coord]bool) []Ship {
+	seen := map[coord.Coord]bool{}
+	var ships []Ship
+	for c := range set {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		stack := []coord.Coord{c}
+		var ship Ship
+		for len(stack) > 0 {
+			cur := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			ship = append(ship, cur)
+			for _, n := range cur.Neighbours(coord.Size, coord.Size) {
+				if set[n] && !seen[n] {
+					seen[n] = true
+					stack = append(stack, n)
+				}
+			}
+		}
+		sortCoords(ship)
+		ships = append(ships, ship)
+	}
+	sort.Slice(ships, func(i, j int) bool {
+		if len(ships[i]) != len(ships[j]) {
+			return len(ships[i]) > len(ships[j])
+		}
+		return less(ships[i][0], ships[j][0])
+	})
+	return ships
+}
+
+func cellSet(cfg Config, cells []coord.Coord) (map[coord.Coord]bool, error) {
+	set := make(map[coord.Coord]bool, len(cells))
+	for _, c := range cells {
+		if !cfg.Contains(c) {
+			return nil, fmt.Errorf("%w: %v", ErrOffBoard, c)
+		}
+		if set[c] {
+			return nil, fmt.Errorf("%w: %v", ErrDuplicate, c)
+		}
+		set[c] = true
+	}
+	return set, nil
+}
+
+func straight(ship Ship) bool {
+	sameX, sameY := true, true
+	for _, c := range ship {
+		sameX = sameX && c.X == ship[0].X
+		sameY = sameY && c.Y == ship[0].Y
+	}
+	return sameX || sameY
+}
+
+// touching reports ships sharing a corner, ships sharing an edge are merged by Ships
+func touching(ships []Ship) error {
+	owner := map[coord.Coord]int{}
+	for i, ship := range ships {
+		for _, c := range ship {
+			owner[c] = i
+		}
+	}
+	for i, ship := range ships {
+		for _, c := range ship {
+			for _, n := range c.Diagonals(coord.Size, coord.Size) {
+				if o, ok := owner[n]; ok && o != i {
+					return ErrTouching
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// withLengths adds the lengths of counts missing from f with zero ships
+func (f Fleet) withLengths(counts map[int]int) Fleet {
+	for l := range counts {
+		if _, ok := f[l]; !ok {
+			f[l] = 0
+		}
+	}
+	return f
+}
+
+func sortCoords(cs []coord.Coord) {
+	sort.Slice(cs, func(i, j int) bool {
+		return less(cs[i], cs[j])
+	})
+}
+
+func less(a, b coord.Coord) bool {
+	if a.X != b.X {
+		return a.X < b.X
+	}
+	return a.Y < b.Y
+}
@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"warships/pkg/coord"
+)
+
+// mini is a small variant with one ship of each length up to three
+var mini = Config{Name: "mini", Width: 5, Height: 5, Fleet: Fleet{3: 1, 2: 1, 1: 1}}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []string
+		want  error
+	}{
+		{name: "valid", cells: []string{"A1", "A2", "A3", "C1", "D1", "E5"}},
+		{name: "shuffled", cells: []string{"E5", "D1", "A3", "A1", "C1", "A2"}},
+		{name: "missing ship", cells: []string{"A1", "A2", "A3", "C1", "D1"}, want: &FleetError{Length: 1, Want: 1, Got: 0}},
+		{name: "extra ship", cells: []string{"A1", "A2", "A3", "C1", "D1", "E5", "C5"}, want: &FleetError{Length: 1, Want: 1, Got: 2}},
+		{name: "ship too long", cells: []string{"A1", "A2", "A3", "A4", "C1", "D1", "E5"}, want: &FleetError{Length: 4, Want: 0, Got: 1}},
+		{name: "bent", cells: []string{"A1", "A2", "B2", "D1", "E1", "E5"}, want: ErrNotStraight},
+		{name: "touching diagonally", cells: []string{"A1", "A2", "A3", "B4", "C4", "E1"}, want: ErrTouching},
+		{name: "off the board", cells: []string{"A1", "A2", "A3", "C1", "D1", "F5"}, want: ErrOffBoard},
+		{name: "field twice", cells: []string{"A1", "A2", "A3", "C1", "D1", "E5", "A1"}, want: ErrDuplicate},
+	}
+	valid := ships("A1 A2 A3", "C1 D1", "E5")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate(mini, coords(tt.cells...))
+			var fe *FleetError
+			switch {
+			case tt.want == nil:
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, valid) {
+					t.Errorf("got ships %v, want %v", got, valid)
+				}
+			case errors.As(tt.want, &fe):
+				if !reflect.DeepEqual(err, tt.want) {
+					t.Errorf("got %v, want %v", err, tt.want)
+				}
+			case !errors.Is(err, tt.want):
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckShip(t *testing.T) {
+	placed := coords("A1", "A2", "A3")
+	tests := []struct {
+		name string
+		ship []string
+		want error
+	}{
+		{"free", []string{"C1", "D1"}, nil},
+		{"single", []string{"E5"}, nil},
+		{"bent", []string{"C1", "D1", "D2"}, ErrNotStraight},
+		{"gap", []string{"C1", "E1"}, ErrNotStraight},
+		{"overlapping", []string{"A3", "B3"}, ErrDuplicate},
+		{"touching", []string{"B4", "C4"}, ErrTouching},
+		{"off the board", []string{"E1", "F1"}, ErrOffBoard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckShip(mini, coords(tt.ship...), placed); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// coords parses fields given as strings
+func coords(s ...string) []coord.Coord {
+	cs := make([]coord.Coord, len(s))
+	for i := range s {
+		cs[i] = coord.MustParse(s[i])
+	}
+	return cs
+}
+
+// ships parses ships given as space separated fields
+func ships(s ...string) []Ship {
+	var ss []Ship
+	for _, fields := range s {
+		ss = append(ss, Ship(coords(strings.Fields(fields)...)))
+	}
+	return ss
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"math/rand"
+	"warships/pkg/api"
+	"warships/pkg/coord"
+	"warships/pkg/layout"
+	"warships/pkg/rules"
+)
+
+// board is one player's fleet together with the shots fired at it
+type board struct {
+	rules *rules.Board
+}
+
+// newBoard validates coords against the variant cfg and builds a board
+func newBoard(cfg rules.Config, coords []string) (*board, error) {
+	cells, err := coord.ParseAll(coords)
+	if err != nil {
+		return nil, err
+	}
+	b, err := rules.NewBoard(cfg, cells)
+	if err != nil {
+		return nil, err
+	}
+	return &board{rules: b}, nil
+}
+
+// randomLayout returns a uniformly random legal layout of the variant cfg
+func randomLayout(cfg rules.Config, rnd *rand.Rand) ([]string, error) {
+	l, err := layout.NewGenerator(cfg, rnd.Int63()).Generate()
+	if err != nil {
+		return nil, err
+	}
+	return l.Strings(), nil
+}
+
+// fire resolves a shot at c, a repeated shot at a sunk ship reports a hit
+func (b *board) fire(c coord.Coord) api.ShotResult {
+	result, err := b.rules.Fire(c)
+	if errors.Is(err, rules.ErrAlreadyShot) && result == rules.Sunk {
+		return api.ShotHit
+	}
+	return api.ShotResult(result)
+}
+
+// fired reports whether c was fired at already
+func (b *board) fired(c coord.Coord) bool {
+	_, ok := b.rules.Result(c)
+	return ok
+}
+
+// sunk reports whether the whole fleet is destroyed
+func (b *board) sunk() bool {
+	return b.rules.Over()
+}
+
+// order returns the coordinates fired at, in order
+func (b *board) order() []string {
+	return coord.Strings(b.rules.Shots())
+}
+
+// shots returns the shots fired at the board together with their results
+func (b *board) shots() []Shot {
+	cells := b.rules.Shots()
+	shots := make([]Shot, len(cells))
+	for i, c := range cells {
+		r, _ := b.rules.Result(c)
+		shots[i] = Shot{Coord: c.String(), Result: api.ShotResult(r)}
+	}
+	return shots
+}
+
+// coords returns the ship coordinates, sorted for stable output
+func (b *board) coords() []string {
+	return coord.Strings(b.rules.Cells())
+}
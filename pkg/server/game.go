@@ -0,0 +1,259 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/coord"
+)
+
+const (
+	statusNoGame     = api.PhaseNoGame
+	statusWaiting    = api.PhaseWaiting
+	statusInProgress = api.PhaseInProgress
+	statusEnded      = api.PhaseEnded
+
+	botNick = "wpbot"
+)
+
+var (
+	errNotYourTurn = errors.New("not your turn")
+	errNoGame      = errors.New("game is not in progress")
+)
+
+// player is a single session identified by its X-Auth-Token
+type player struct {
+	token      string
+	nick       string
+	desc       string
+	targetNick string
+	board      *board
+	game       *game
+	status     api.GamePhase
+	lastGame   api.GameOutcome
+	lastSeen   time.Time
+	bot        Opponent
+}
+
+// game is a match between two players, the second one may be a bot
+type game struct {
+	id        string
+	players   [2]*player
+	turn      int
+	turnStart time.Time
+	status    api.GamePhase
+	ended     time.Time
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (g *game) other(p *player) *player {
+	if g.players[0] == p {
+		return g.players[1]
+	}
+	return g.players[0]
+}
+
+func (g *game) current() *player {
+	return g.players[g.turn]
+}
+
+// startGame pairs host and guest, the host fires first
+func (s *Server) startGame(host, guest *player) {
+	g := &game{
+		id:        newToken()[:8],
+		players:   [2]*player{host, guest},
+		turnStart: s.now(),
+		status:    statusInProgress,
+	}
+	for _, p := range g.players {
+		p.game = g
+		p.status = statusInProgress
+	}
+	s.games = append(s.games, g)
+}
+
+// fire resolves a shot of p and lets the bot answer if it is its turn afterwards
+func (s *Server) fire(p *player, target string) (api.ShotResult, error) {
+	g := p.game
+	if g == nil || g.status != statusInProgress {
+		return "", errNoGame
+	}
+	if g.current() != p {
+		return "", errNotYourTurn
+	}
+	c, err := s.cfg.Rules.Parse(target)
+	if err != nil {
+		return "", err
+	}
+
+	result := s.shoot(g, c)
+	s.playBot(g)
+	return result, nil
+}
+
+// shoot fires at c on behalf of the player whose turn it is
+func (s *Server) shoot(g *game, c coord.Coord) api.ShotResult {
+	target := g.other(g.current())
+	result := target.board.fire(c)
+	switch {
+	case target.board.sunk():
+		s.endGame(g, g.current())
+	case result == api.ShotMiss:
+		g.turn = 1 - g.turn
+		g.turnStart = s.now()
+	}
+	return result
+}
+
+// playBot fires bot shots for as long as it is the bot's turn
+func (s *Server) playBot(g *game) {
+	for g.status == statusInProgress && g.current().bot != nil {
+		bot := g.current()
+		target := g.other(bot)
+		c, err := s.cfg.Rules.Parse(bot.bot.Shoot(s.cfg.Rules, target.board.shots()))
+		if err != nil || target.board.fired(c) {
+			// a broken bot forfeits its turn instead of hanging the game, a
+			// repeated shot would keep the turn of a hit forever
+			g.turn = 1 - g.turn
+			g.turnStart = s.now()
+			continue
+		}
+		s.shoot(g, c)
+	}
+}
+
+// endGame finishes g and updates the rankings
+func (s *Server) endGame(g *game, winner *player) {
+	g.status = statusEnded
+	g.ended = s.now()
+	for _, p := range g.players {
+		p.status = statusEnded
+		p.lastGame = api.OutcomeLose
+		if p == winner {
+			p.lastGame = api.OutcomeWin
+		}
+		if p.bot != nil {
+			continue
+		}
+		st := s.stat(p.nick)
+		st.Games++
+		if p == winner {
+			st.Wins++
+			st.Points += 3
+		}
+	}
+	s.rank()
+}
+
+func (s *Server) stat(nick string) *api.GameStat {
+	st, ok := s.stats[nick]
+	if !ok {
+		st = &api.GameStat{Nick: nick}
+		s.stats[nick] = st
+	}
+	return st
+}
+
+// rank recomputes player ranks by points, then wins, then nick
+func (s *Server) rank() {
+	for i, st := range s.ranking() {
+		st.Rank = i + 1
+	}
+}
+
+func (s *Server) ranking() []*api.GameStat {
+	stats := make([]*api.GameStat, 0, len(s.stats))
+	for _, st := range s.stats {
+		stats = append(stats, st)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Points != stats[j].Points {
+			return stats[i].Points > stats[j].Points
+		}
+		if stats[i].Wins != stats[j].Wins {
+			return stats[i].Wins > stats[j].Wins
+		}
+		return stats[i].Nick < stats[j].Nick
+	})
+	return stats
+}
+
+// tick applies the turn and lobby timers and drops the games that ended
+// long enough ago together with their sessions, it is called before every request
+func (s *Server) tick() {
+	now := s.now()
+	games := s.games[:0]
+	for _, g := range s.games {
+		if g.status == statusInProgress && now.Sub(g.turnStart) > s.cfg.TurnTimeout {
+			s.endGame(g, g.other(g.current()))
+		}
+		if g.status != statusEnded || now.Sub(g.ended) <= s.cfg.EndedTimeout {
+			games = append(games, g)
+		}
+	}
+	for i := len(games); i < len(s.games); i++ {
+		s.games[i] = nil
+	}
+	s.games = games
+	for token, p := range s.players {
+		switch {
+		case p.status == statusWaiting && now.Sub(p.lastSeen) > s.cfg.LobbyTimeout:
+			delete(s.players, token)
+		case p.game != nil && p.game.status == statusEnded && now.Sub(p.game.ended) > s.cfg.EndedTimeout:
+			delete(s.players, token)
+		}
+	}
+}
+
+// status builds the GET /game response for p
+func (s *Server) status(p *player) api.GameStatus {
+	st := api.GameStatus{
+		GameStatus:     p.status,
+		LastGameStatus: p.lastGame,
+		Nick:           p.nick,
+		OppShots:       []string{},
+	}
+	if st.LastGameStatus == "" {
+		st.LastGameStatus = api.OutcomeNoGame
+	}
+	if g := p.game; g != nil {
+		st.Opponent = g.other(p).nick
+		st.OppShots = append(st.OppShots, p.board.order()...)
+		if g.status == statusInProgress {
+			st.ShouldFire = g.current() == p
+			left := s.cfg.TurnTimeout - s.now().Sub(g.turnStart)
+			st.Timer = int(left.Round(time.Second) / time.Second)
+		}
+	}
+	return st
+}
+
+// waitingPlayer finds a lobby player with the given nick willing to play against nick me
+func (s *Server) waitingPlayer(nick, me string) *player {
+	for _, p := range s.players {
+		if p.status == statusWaiting && p.nick == nick && (p.targetNick == "" || p.targetNick == me) {
+			return p
+		}
+	}
+	return nil
+}
+
+// challenger finds a lobby player who challenged nick
+func (s *Server) challenger(nick string) *player {
+	for _, p := range s.players {
+		if p.status == statusWaiting && p.targetNick == nick {
+			return p
+		}
+	}
+	return nil
+}
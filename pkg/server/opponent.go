@@ -0,0 +1,122 @@
+package server
+
+import (
+	"math/rand"
+	"warships/pkg/api"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/strategy"
+)
+
+// Shot is a shot already fired by the opponent together with its result
+type Shot struct {
+	Coord  string
+	Result api.ShotResult
+}
+
+// Opponent plays the wpbot side of a bot game
+type Opponent interface {
+	// Layout returns the coordinates of the opponent's ships for the variant cfg
+	Layout(cfg rules.Config) []string
+	// Shoot returns the next coordinate to fire at, given the shots fired so far
+	Shoot(cfg rules.Config, shots []Shot) string
+}
+
+type randomOpponent struct {
+	rnd *rand.Rand
+}
+
+// RandomOpponent returns an opponent with a random layout that fires at random untouched cells
+func RandomOpponent(seed int64) Opponent {
+	return &randomOpponent{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (o *randomOpponent) Layout(cfg rules.Config) []string {
+	// an empty layout is rejected by the server
+	coords, _ := randomLayout(cfg, o.rnd)
+	return coords
+}
+
+func (o *randomOpponent) Shoot(cfg rules.Config, shots []Shot) string {
+	fired := map[string]bool{}
+	for _, s := range shots {
+		fired[s.Coord] = true
+	}
+	var free []string
+	for x := 0; x < cfg.Width; x++ {
+		for y := 0; y < cfg.Height; y++ {
+			if c := coord.FromIndex(x, y).String(); !fired[c] {
+				free = append(free, c)
+			}
+		}
+	}
+	if len(free) == 0 {
+		return "A1"
+	}
+	return free[o.rnd.Intn(len(free))]
+}
+
+type strategyOpponent struct {
+	strategy strategy.Strategy
+	rnd      *rand.Rand
+}
+
+// StrategyOpponent returns an opponent with a random layout that fires as s
+func StrategyOpponent(s strategy.Strategy, seed int64) Opponent {
+	return &strategyOpponent{strategy: s, rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (o *strategyOpponent) Layout(cfg rules.Config) []string {
+	coords, _ := randomLayout(cfg, o.rnd)
+	return coords
+}
+
+func (o *strategyOpponent) Shoot(cfg rules.Config, shots []Shot) string {
+	known := make([]strategy.Shot, 0, len(shots))
+	for _, s := range shots {
+		c, err := cfg.Parse(s.Coord)
+		if err != nil {
+			continue
+		}
+		known = append(known, strategy.Shot{Coord: c, Result: rules.Result(s.Result)})
+	}
+	board, remaining := strategy.Board(cfg, known)
+	c, err := o.strategy.Next(cfg, board, remaining)
+	if err != nil {
+		return firstFree(cfg, shots)
+	}
+	return c.String()
+}
+
+// ScriptedOpponent is an opponent with a fixed layout that fires Shots in order.
+// When the script runs out it fires at the first untouched cell, A1, A2, ...
+// Like any bot it forfeits its turn by firing at a field twice.
+type ScriptedOpponent struct {
+	Ships []string
+	Shots []string
+}
+
+func (o ScriptedOpponent) Layout(cfg rules.Config) []string {
+	return o.Ships
+}
+
+func (o ScriptedOpponent) Shoot(cfg rules.Config, shots []Shot) string {
+	if len(shots) < len(o.Shots) {
+		return o.Shots[len(shots)]
+	}
+	return firstFree(cfg, shots)
+}
+
+// firstFree returns the first field not fired at, A1, A2, ...
+func firstFree(cfg rules.Config, shots []Shot) string {
+	fired := map[string]bool{}
+	for _, s := range shots {
+		fired[s.Coord] = true
+	}
+	for _, c := range cfg.Fields() {
+		if !fired[c.String()] {
+			return c.String()
+		}
+	}
+	return "A1"
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"testing"
+	"warships/pkg/api"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+	"warships/pkg/strategy"
+)
+
+// stuck is a strategy that never finds a field
+type stuck struct{}
+
+func (stuck) Next(rules.Config, state.Grid, rules.Fleet) (coord.Coord, error) {
+	return coord.Coord{}, strategy.ErrNoTarget
+}
+
+func TestOpponentFallback(t *testing.T) {
+	cfg := rules.Classic()
+	tests := []struct {
+		name  string
+		shots []Shot
+		want  string
+	}{
+		{"no shots", nil, "A1"},
+		{"A1 hit", []Shot{{Coord: "A1", Result: api.ShotHit}}, "A2"},
+		{"first column shot", []Shot{
+			{Coord: "A1", Result: api.ShotMiss}, {Coord: "A2", Result: api.ShotMiss}, {Coord: "A3", Result: api.ShotMiss},
+			{Coord: "A4", Result: api.ShotMiss}, {Coord: "A5", Result: api.ShotMiss}, {Coord: "A6", Result: api.ShotMiss},
+			{Coord: "A7", Result: api.ShotMiss}, {Coord: "A8", Result: api.ShotMiss}, {Coord: "A9", Result: api.ShotMiss},
+			{Coord: "A10", Result: api.ShotMiss},
+		}, "B1"},
+	}
+	opponents := map[string]Opponent{
+		"strategy": StrategyOpponent(stuck{}, 1),
+		"scripted": ScriptedOpponent{},
+	}
+	for name, o := range opponents {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				if got := o.Shoot(cfg, tt.shots); got != tt.want {
+					t.Errorf("got %s, want %s", got, tt.want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// Package server implements the warships REST protocol consumed by api.Client:
+// X-Auth-Token sessions, the lobby, target_nick challenges, wpbot games, turn
+// and lobby timers, rankings and the game list. It backs both the standalone
+// cmd/server binary and the apitest fake used in tests.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/rules"
+)
+
+// Prefix is the path under which the API is served, like on the public server
+const Prefix = "/api"
+
+// Config configures a Server
+type Config struct {
+	// TurnTimeout is the time a player has to fire, defaults to 60s
+	TurnTimeout time.Duration
+	// LobbyTimeout is how long a waiting session lives without a refresh, defaults to 60s
+	LobbyTimeout time.Duration
+	// EndedTimeout is how long an ended game and its sessions are kept, defaults to 5m
+	EndedTimeout time.Duration
+	// Rules is the variant played, defaults to the classic game
+	Rules rules.Config
+	// Opponent returns the wpbot opponent of a new bot game, defaults to RandomOpponent
+	Opponent func() Opponent
+	// Clock returns the current time, defaults to time.Now
+	Clock func() time.Time
+}
+
+// Server is a warships game server, it is safe for concurrent use
+type Server struct {
+	mu      sync.Mutex
+	cfg     Config
+	rnd     *rand.Rand
+	players map[string]*player
+	games   []*game
+	stats   map[string]*api.GameStat
+}
+
+// New returns a Server, serve it with net/http. It panics if cfg.Rules cannot be played.
+func New(cfg Config) *Server {
+	if cfg.TurnTimeout <= 0 {
+		cfg.TurnTimeout = 60 * time.Second
+	}
+	if cfg.LobbyTimeout <= 0 {
+		cfg.LobbyTimeout = 60 * time.Second
+	}
+	if cfg.EndedTimeout <= 0 {
+		cfg.EndedTimeout = 5 * time.Minute
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = time.Now
+	}
+	if cfg.Rules.Width == 0 {
+		cfg.Rules = rules.Classic()
+	}
+	if err := cfg.Rules.Check(); err != nil {
+		panic(err)
+	}
+	s := &Server{
+		cfg:     cfg,
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		players: map[string]*player{},
+		stats:   map[string]*api.GameStat{},
+	}
+	if s.cfg.Opponent == nil {
+		s.cfg.Opponent = func() Opponent { return RandomOpponent(s.rnd.Int63()) }
+	}
+	return s
+}
+
+// Tick applies the turn and lobby timers and drops ended games. Requests do it on their own, call
+// Tick periodically so that idle games still time out.
+func (s *Server) Tick() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tick()
+}
+
+// Expire drops the session of token, as if it had timed out. A game in
+// progress ends first, won by the opponent.
+func (s *Server) Expire(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.players[token]; ok && p.game != nil && p.game.status == statusInProgress {
+		s.endGame(p.game, p.game.other(p))
+	}
+	delete(s.players, token)
+}
+
+func (s *Server) now() time.Time {
+	return s.cfg.Clock()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, Prefix)
+	s.tick()
+
+	switch {
+	case path == api.GameURL && r.Method == http.MethodPost:
+		s.handleStartGame(w, r)
+	case path == api.GameURL && r.Method == http.MethodGet:
+		s.authorized(w, r, func(p *player) { writeJSON(w, http.StatusOK, s.status(p)) })
+	case path == api.BoardURL && r.Method == http.MethodGet:
+		s.authorized(w, r, s.handleBoard(w))
+	case path == api.FireURL && r.Method == http.MethodPost:
+		s.authorized(w, r, s.handleFire(w, r))
+	case path == api.AbandonGameURL && r.Method == http.MethodDelete:
+		s.authorized(w, r, s.handleAbandon(w))
+	case path == api.GameDescURL && r.Method == http.MethodGet:
+		s.authorized(w, r, s.handleDescription(w))
+	case path == api.RefreshURL && r.Method == http.MethodGet:
+		s.authorized(w, r, func(p *player) {
+			p.lastSeen = s.now()
+			w.WriteHeader(http.StatusOK)
+		})
+	case path == api.ListURL && r.Method == http.MethodGet:
+		s.handleList(w, r)
+	case path == api.LobbyURL && r.Method == http.MethodGet:
+		s.handleLobby(w)
+	case path == api.StatsURL && r.Method == http.MethodGet:
+		s.handleTopStats(w)
+	case strings.HasPrefix(path, api.StatsURL+"/") && r.Method == http.MethodGet:
+		s.handlePlayerStats(w, strings.TrimPrefix(path, api.StatsURL+"/"))
+	default:
+		writeError(w, http.StatusNotFound, "no such endpoint")
+	}
+}
+
+// authorized runs handle for the session of the request token, or answers 401
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request, handle func(p *player)) {
+	p, ok := s.players[r.Header.Get("X-Auth-Token")]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "session not found")
+		return
+	}
+	handle(p)
+}
+
+func (s *Server) handleStartGame(w http.ResponseWriter, r *http.Request) {
+	var data api.StartGameData
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	coords := data.Coords
+	if len(coords) == 0 {
+		var err error
+		if coords, err = randomLayout(s.cfg.Rules, s.rnd); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	b, err := newBoard(s.cfg.Rules, coords)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if data.Nick == "" {
+		data.Nick = fmt.Sprintf("player_%d", s.rnd.Intn(100000))
+	}
+
+	p := &player{
+		token:      newToken(),
+		nick:       data.Nick,
+		desc:       data.Desc,
+		targetNick: data.TargetNick,
+		board:      b,
+		status:     statusWaiting,
+		lastSeen:   s.now(),
+	}
+
+	switch {
+	case data.WPBot:
+		opp := s.cfg.Opponent()
+		ob, err := newBoard(s.cfg.Rules, opp.Layout(s.cfg.Rules))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "invalid bot layout: "+err.Error())
+			return
+		}
+		s.startGame(p, &player{nick: botNick, desc: "WP bot", board: ob, bot: opp})
+	case data.TargetNick != "":
+		if host := s.waitingPlayer(data.TargetNick, data.Nick); host != nil {
+			s.startGame(host, p)
+		}
+	default:
+		if host := s.challenger(data.Nick); host != nil {
+			s.startGame(host, p)
+		}
+	}
+
+	s.players[p.token] = p
+	w.Header().Set("X-Auth-Token", p.token)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleBoard(w http.ResponseWriter) func(p *player) {
+	return func(p *player) {
+		writeJSON(w, http.StatusOK, api.GameBoard{Board: p.board.coords()})
+	}
+}
+
+func (s *Server) handleFire(w http.ResponseWriter, r *http.Request) func(p *player) {
+	return func(p *player) {
+		var data api.FireData
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		result, err := s.fire(p, data.Coord)
+		switch err {
+		case nil:
+			writeJSON(w, http.StatusOK, api.FireResult{Result: result})
+		case errNotYourTurn, errNoGame:
+			writeError(w, http.StatusForbidden, err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+	}
+}
+
+func (s *Server) handleAbandon(w http.ResponseWriter) func(p *player) {
+	return func(p *player) {
+		switch {
+		case p.game != nil && p.game.status == statusInProgress:
+			s.endGame(p.game, p.game.other(p))
+		case p.status == statusWaiting:
+			p.status = statusNoGame
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func (s *Server) handleDescription(w http.ResponseWriter) func(p *player) {
+	return func(p *player) {
+		d := api.GameDescription{Nick: p.nick, Desc: p.desc}
+		if p.game != nil {
+			opp := p.game.other(p)
+			d.Opponent = opp.nick
+			d.OppDesc = opp.desc
+		}
+		writeJSON(w, http.StatusOK, d)
+	}
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	list := api.GameList{}
+	for _, g := range s.games {
+		if status != "" && g.status != api.GamePhase(status) {
+			continue
+		}
+		list = append(list, struct {
+			Guest  string        `json:"guest"`
+			Host   string        `json:"host"`
+			ID     string        `json:"id"`
+			Status api.GamePhase `json:"status"`
+		}{Guest: g.players[1].nick, Host: g.players[0].nick, ID: g.id, Status: g.status})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleLobby(w http.ResponseWriter) {
+	lobby := []api.LobbyPlayer{}
+	for _, p := range s.players {
+		if p.status == statusWaiting {
+			lobby = append(lobby, api.LobbyPlayer{GameStatus: p.status, Nick: p.nick})
+		}
+	}
+	writeJSON(w, http.StatusOK, lobby)
+}
+
+func (s *Server) handleTopStats(w http.ResponseWriter) {
+	top := api.TopPlayerStats{Stats: []api.PlayerStats{}}
+	for i, st := range s.ranking() {
+		if i == 10 {
+			break
+		}
+		top.Stats = append(top.Stats, api.PlayerStats(*st))
+	}
+	writeJSON(w, http.StatusOK, top)
+}
+
+func (s *Server) handlePlayerStats(w http.ResponseWriter, nick string) {
+	st, ok := s.stats[nick]
+	if !ok {
+		writeError(w, http.StatusNotFound, "player not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Stats api.GameStat `json:"stats"`
+	}{*st})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, api.ErrorMessage{Message: msg})
+}
@@ -0,0 +1,173 @@
+package server_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/server"
+)
+
+// clock is a server clock moved by hand
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newServer starts a server on a fake clock with the given timeouts
+func newServer(t *testing.T, turn, ended time.Duration) (*server.Server, *clock, string) {
+	t.Helper()
+	clk := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	srv := server.New(server.Config{TurnTimeout: turn, EndedTimeout: ended, Clock: clk.Now})
+	hs := httptest.NewServer(srv)
+	t.Cleanup(hs.Close)
+	return srv, clk, hs.URL + server.Prefix
+}
+
+func TestEndedGamesAreDropped(t *testing.T) {
+	tests := []struct {
+		name string
+		// end ends the game of c
+		end func(c *api.Client, clk *clock) error
+	}{
+		{"abandoned", func(c *api.Client, _ *clock) error {
+			return c.AbandonGame(context.Background())
+		}},
+		{"timed out", func(_ *api.Client, clk *clock) error {
+			clk.Advance(2 * time.Minute)
+			return nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			srv, clk, url := newServer(t, time.Minute, 5*time.Minute)
+			c := api.NewClient(api.WithBaseURL(url), api.WithRetryPolicy(api.NoRetry()))
+			if _, err := c.StartGame(ctx, "tester", "", "", nil, true); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.end(c, clk); err != nil {
+				t.Fatal(err)
+			}
+
+			status, err := c.GetGameStatus(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.GameStatus != api.PhaseEnded || status.LastGameStatus != api.OutcomeLose {
+				t.Fatalf("got status %+v", status)
+			}
+
+			clk.Advance(4 * time.Minute)
+			if _, err := c.GetGameStatus(ctx); err != nil {
+				t.Fatalf("the session is gone before the grace period: %v", err)
+			}
+			if list, err := c.GetAllGames(ctx, ""); err != nil || len(list) != 1 {
+				t.Fatalf("got games %v, %v", list, err)
+			}
+
+			clk.Advance(2 * time.Minute)
+			srv.Tick()
+			if list, err := c.GetAllGames(ctx, ""); err != nil || len(list) != 0 {
+				t.Errorf("got games %v, %v", list, err)
+			}
+			if _, err := c.GetGameStatus(ctx); !api.IsUnauthorized(err) {
+				t.Errorf("got %v, want the session dropped", err)
+			}
+		})
+	}
+}
+
+func TestExpireEndsGame(t *testing.T) {
+	ctx := context.Background()
+	srv, _, url := newServer(t, time.Minute, 5*time.Minute)
+	host := api.NewClient(api.WithBaseURL(url), api.WithRetryPolicy(api.NoRetry()))
+	guest := api.NewClient(api.WithBaseURL(url), api.WithRetryPolicy(api.NoRetry()))
+	if _, err := host.StartGame(ctx, "host", "", "guest", nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := guest.StartGame(ctx, "guest", "", "host", nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if status, err := guest.GetGameStatus(ctx); err != nil || status.GameStatus != api.PhaseInProgress {
+		t.Fatalf("got status %+v, %v", status, err)
+	}
+
+	srv.Expire(host.Token)
+	if _, err := host.GetGameStatus(ctx); !api.IsUnauthorized(err) {
+		t.Errorf("got %v, want the session dropped", err)
+	}
+	status, err := guest.GetGameStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.GameStatus != api.PhaseEnded || status.LastGameStatus != api.OutcomeWin || status.Opponent != "host" {
+		t.Errorf("got status %+v, want a won game", status)
+	}
+	if list, err := guest.GetAllGames(ctx, string(api.PhaseInProgress)); err != nil || len(list) != 0 {
+		t.Errorf("got games in progress %v, %v", list, err)
+	}
+}
+
+func TestBotRepeatedShotForfeitsTurn(t *testing.T) {
+	ships := []string{
+		"A1", "A2", "A3", "A4",
+		"A6", "A7", "A8", "C1", "C2", "C3",
+		"C5", "C6", "C8", "C9", "E1", "E2",
+		"E4", "E6", "E8", "E10",
+	}
+	tests := []struct {
+		name  string
+		shots []string
+		want  []string
+	}{
+		{"repeated hit", []string{"A1", "A1"}, []string{"A1"}},
+		{"repeated miss", []string{"J10", "B1", "J10"}, []string{"J10", "B1"}},
+		{"off the board", []string{"K1"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			srv := server.New(server.Config{Opponent: func() server.Opponent {
+				return server.ScriptedOpponent{Ships: ships, Shots: tt.shots}
+			}})
+			hs := httptest.NewServer(srv)
+			defer hs.Close()
+			c := api.NewClient(api.WithBaseURL(hs.URL+server.Prefix), api.WithRetryPolicy(api.NoRetry()), api.WithTimeout(5*time.Second))
+			if _, err := c.StartGame(ctx, "tester", "", "", ships, true); err != nil {
+				t.Fatal(err)
+			}
+			// every miss lets the bot fire one turn of its script
+			for _, f := range []string{"J1", "J3", "J5"} {
+				if _, err := c.Fire(ctx, api.FireData{Coord: f}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			status, err := c.GetGameStatus(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !status.ShouldFire || status.GameStatus != api.PhaseInProgress {
+				t.Errorf("got status %+v, want the player to fire", status)
+			}
+			if len(status.OppShots) < len(tt.want) || !reflect.DeepEqual(status.OppShots[:len(tt.want)], tt.want) {
+				t.Errorf("got opponent shots %v, want them to start with %v", status.OppShots, tt.want)
+			}
+		})
+	}
+}
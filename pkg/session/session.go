@@ -0,0 +1,97 @@
+// Package session persists the running game so that it can be resumed after
+// the client is restarted.
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+	"warships/pkg/api"
+	"warships/pkg/state"
+)
+
+// ErrNoSession is returned by Load when there is nothing to resume
+var ErrNoSession = errors.New("no saved session")
+
+// Session is everything needed to reattach to a running game
+type Session struct {
+	Token     string           `json:"token"`
+	Server    string           `json:"server"`
+	Opponent  string           `json:"opponent"`
+	Bot       bool             `json:"bot"`
+	StartedAt time.Time        `json:"started_at"`
+	Shots     []api.ShotRecord `json:"shots"`
+	State     state.Snapshot   `json:"state"`
+	// Record is the file the game is recorded to, empty if it is not
+	Record string `json:"record,omitempty"`
+}
+
+// Store keeps a single session in a file
+type Store struct {
+	Path string
+}
+
+// ConfigDir returns the directory holding the client's files
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "wrshps"), nil
+}
+
+// DefaultStore returns a store in the user's config directory
+func DefaultStore() (*Store, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Path: filepath.Join(dir, "session.json")}, nil
+}
+
+// Save writes the session, replacing the previous one
+func (s *Store) Save(sess Session) error {
+	data, err := json.MarshalIndent(sess, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil {
+		return err
+	}
+	// the token is a credential, keep it private and never leave a half written file
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// Load reads the saved session, it returns ErrNoSession if there is none
+func (s *Store) Load() (*Session, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoSession
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sess Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, err
+	}
+	if sess.Token == "" {
+		return nil, ErrNoSession
+	}
+	return &sess, nil
+}
+
+// Clear removes the saved session
+func (s *Store) Clear() error {
+	err := os.Remove(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
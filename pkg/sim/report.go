@@ -0,0 +1,75 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Stats describes the shots one player needed to sink the whole fleet
+type Stats struct {
+	Wins    int     `json:"wins"`
+	WinRate float64 `json:"win_rate"`
+	Mean    float64 `json:"mean"`
+	Median  float64 `json:"median"`
+	P95     int     `json:"p95"`
+	Min     int     `json:"min"`
+	Max     int     `json:"max"`
+	// Histogram counts the games by the number of shots needed
+	Histogram map[int]int `json:"histogram"`
+}
+
+// Result sums up the games of a matchup
+type Result struct {
+	Matchup
+	Games  int   `json:"games"`
+	StatsA Stats `json:"stats_a"`
+	StatsB Stats `json:"stats_b"`
+}
+
+func summarize(m Matchup, outcomes []outcome) Result {
+	r := Result{Matchup: m, Games: len(outcomes)}
+	for i, st := range []*Stats{&r.StatsA, &r.StatsB} {
+		shots := make([]int, len(outcomes))
+		st.Histogram = map[int]int{}
+		for j, o := range outcomes {
+			shots[j] = o.shots[i]
+			st.Histogram[o.shots[i]]++
+			if o.winner() == i {
+				st.Wins++
+			}
+		}
+		if len(shots) == 0 {
+			continue
+		}
+		sort.Ints(shots)
+		total := 0
+		for _, s := range shots {
+			total += s
+		}
+		n := len(shots)
+		st.WinRate = float64(st.Wins) / float64(n)
+		st.Mean = float64(total) / float64(n)
+		st.Median = float64(shots[(n-1)/2]+shots[n/2]) / 2
+		st.P95 = shots[(n*95+99)/100-1]
+		st.Min, st.Max = shots[0], shots[n-1]
+	}
+	return r
+}
+
+// WriteTable writes one line per player of every matchup
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "player\tvs\tgames\twin rate\tmean\tmedian\tp95\tmin\tmax\t")
+	for _, r := range results {
+		for _, side := range []struct {
+			p, vs Player
+			st    Stats
+		}{{r.A, r.B, r.StatsA}, {r.B, r.A, r.StatsB}} {
+			fmt.Fprintf(tw, "%v\t%v\t%d\t%.1f%%\t%.2f\t%.1f\t%d\t%d\t%d\t\n",
+				side.p, side.vs, r.Games, side.st.WinRate*100, side.st.Mean, side.st.Median, side.st.P95, side.st.Min, side.st.Max)
+		}
+	}
+	return tw.Flush()
+}
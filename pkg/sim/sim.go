@@ -0,0 +1,261 @@
+// Package sim plays games between strategies entirely in memory to measure
+// how many shots they need and how often they win.
+package sim
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"warships/pkg/layout"
+	"warships/pkg/rules"
+	"warships/pkg/strategy"
+)
+
+// Uniform is the generator drawing every legal layout with the same chance,
+// the other generators are the styles of layout.Styles
+const Uniform = "uniform"
+
+// Player is a strategy firing at the opponent together with the generator
+// placing its own fleet
+type Player struct {
+	Strategy  string  `json:"strategy"`
+	Noise     float64 `json:"noise,omitempty"`
+	Generator string  `json:"generator"`
+}
+
+// ParsePlayer reads a player written as strategy[:noise][/generator], e.g.
+// density, hunt:0.2 or parity/edge-hugger
+func ParsePlayer(s string) (Player, error) {
+	p := Player{Generator: Uniform}
+	s, gen, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if ok {
+		p.Generator = gen
+	}
+	name, noise, ok := strings.Cut(s, ":")
+	p.Strategy = name
+	if ok {
+		n, err := strconv.ParseFloat(noise, 64)
+		if err != nil {
+			return Player{}, fmt.Errorf("noise of %s: %w", name, err)
+		}
+		p.Noise = n
+	}
+	if _, err := strategy.New(p.Strategy, strategy.Options{Noise: p.Noise}); err != nil {
+		return Player{}, err
+	}
+	if _, ok := generators()[p.Generator]; !ok && p.Generator != Uniform {
+		return Player{}, fmt.Errorf("unknown generator %q, expected one of %s", p.Generator, strings.Join(Generators(), ", "))
+	}
+	return p, nil
+}
+
+func (p Player) String() string {
+	s := p.Strategy
+	if p.Noise != 0 {
+		s += ":" + strconv.FormatFloat(p.Noise, 'g', -1, 64)
+	}
+	return s + "/" + p.Generator
+}
+
+// Generators returns the names of the fleet generators
+func Generators() []string {
+	names := []string{Uniform}
+	for name := range generators() {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+func generators() map[string]layout.Bias {
+	return layout.Styles()
+}
+
+func (p Player) generator(cfg rules.Config, seed int64) *layout.Generator {
+	if bias, ok := generators()[p.Generator]; ok {
+		return layout.NewStrategicGenerator(cfg, seed, bias)
+	}
+	return layout.NewGenerator(cfg, seed)
+}
+
+// Matchup is a series of games between two players
+type Matchup struct {
+	A Player `json:"a"`
+	B Player `json:"b"`
+}
+
+// Config sets up a simulation
+type Config struct {
+	Rules rules.Config
+	// Games is the number of games of every matchup, A starts every other game
+	Games int
+	// Seed makes the whole simulation reproducible
+	Seed int64
+	// Workers is the number of games played at once, defaults to the number of CPUs
+	Workers int
+}
+
+// outcome is how a single game went for both players
+type outcome struct {
+	shots [2]int
+	// turns is the turn in which a player sank the last ship
+	turns [2]int
+	first int
+}
+
+// winner applies the turn order: a hit lets the player fire again, so every
+// turn ends with a miss except the winning one
+func (o outcome) winner() int {
+	second := 1 - o.first
+	if o.turns[o.first] <= o.turns[second] {
+		return o.first
+	}
+	return second
+}
+
+// Run plays every matchup and returns their results in the same order
+func Run(ctx context.Context, cfg Config, matchups []Matchup) ([]Result, error) {
+	if err := cfg.Rules.Check(); err != nil {
+		return nil, err
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type job struct{ m, g int }
+	outcomes := make([][]outcome, len(matchups))
+	for i := range outcomes {
+		outcomes[i] = make([]outcome, cfg.Games)
+	}
+	jobs := make(chan job)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				o, err := play(cfg.Rules, matchups[j.m], gameSeed(cfg.Seed, j.m, j.g), j.g%2)
+				if err != nil {
+					errs <- err
+					return
+				}
+				outcomes[j.m][j.g] = o
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for m := range matchups {
+		for g := 0; g < cfg.Games; g++ {
+			select {
+			case jobs <- job{m, g}:
+			case err = <-errs:
+				break feed
+			case <-ctx.Done():
+				err = ctx.Err()
+				break feed
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err == nil {
+		select {
+		case err = <-errs:
+		default:
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(matchups))
+	for i, m := range matchups {
+		results[i] = summarize(m, outcomes[i])
+	}
+	return results, nil
+}
+
+// gameSeed derives independent seeds for every game of every matchup.
+// math/rand reduces its seed modulo 2^31-1, so the parts are mixed into
+// every bit rather than shifted apart.
+func gameSeed(seed int64, m, g int) int64 {
+	x := mix(uint64(seed))
+	x = mix(x + uint64(m))
+	x = mix(x + uint64(g))
+	return int64(x)
+}
+
+// mix is the finalizer of splitmix64
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// play plays one game. The players do not see each other's shots, so each
+// one clears the opponent's fleet on its own and the turn order decides the
+// winner afterwards.
+func play(cfg rules.Config, m Matchup, seed int64, first int) (outcome, error) {
+	rnd := rand.New(rand.NewSource(seed))
+	players := [2]Player{m.A, m.B}
+	o := outcome{first: first}
+	for i, p := range players {
+		opponent := players[1-i]
+		l, err := opponent.generator(cfg, rnd.Int63()).Generate()
+		if err != nil {
+			return o, err
+		}
+		s, err := strategy.New(p.Strategy, strategy.Options{Noise: p.Noise, Seed: rnd.Int63()})
+		if err != nil {
+			return o, err
+		}
+		o.shots[i], o.turns[i], err = sink(cfg, s, l)
+		if err != nil {
+			return o, fmt.Errorf("%v: %w", p, err)
+		}
+	}
+	return o, nil
+}
+
+// sink fires with s at the fleet l until it is sunk and returns the shots
+// and turns it took
+func sink(cfg rules.Config, s strategy.Strategy, l layout.Layout) (shots, turns int, err error) {
+	board, err := rules.NewBoard(cfg, l)
+	if err != nil {
+		return 0, 0, err
+	}
+	var known []strategy.Shot
+	turns = 1
+	// a strategy firing at the same fields forever must not hang the simulation
+	limit := 10 * cfg.Width * cfg.Height
+	for !board.Over() {
+		if len(known) >= limit {
+			return 0, 0, fmt.Errorf("fleet not sunk after %d shots", limit)
+		}
+		grid, remaining := strategy.Board(cfg, known)
+		c, err := s.Next(cfg, grid, remaining)
+		if err != nil {
+			return 0, 0, err
+		}
+		result, err := board.Fire(c)
+		if err != nil || !cfg.Contains(c) {
+			// a wasted shot ends the turn like a miss
+			result = rules.Miss
+		}
+		known = append(known, strategy.Shot{Coord: c, Result: result})
+		if result == rules.Miss && !board.Over() {
+			turns++
+		}
+	}
+	return len(known), turns, nil
+}
@@ -0,0 +1,46 @@
+package sim
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"warships/pkg/rules"
+)
+
+func TestGameSeedsDifferBetweenMatchups(t *testing.T) {
+	seen := map[int64]bool{}
+	for m := 0; m < 4; m++ {
+		for g := 0; g < 100; g++ {
+			// math/rand only keeps the seed modulo 2^31-1
+			s := gameSeed(1, m, g) % (1<<31 - 1)
+			if seen[s] {
+				t.Fatalf("game %d of matchup %d repeats a seed", g, m)
+			}
+			seen[s] = true
+		}
+	}
+}
+
+func TestMatchupsPlayDifferentGames(t *testing.T) {
+	p, err := ParsePlayer("random")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Matchup{A: p, B: p}
+	cfg := Config{Rules: rules.Classic(), Games: 50, Seed: 1, Workers: 2}
+	results, err := Run(context.Background(), cfg, []Matchup{m, m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(results[0].StatsA, results[1].StatsA) {
+		t.Errorf("both matchups played the same games: %+v", results[0].StatsA)
+	}
+
+	again, err := Run(context.Background(), cfg, []Matchup{m, m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(results, again) {
+		t.Error("the same seed played different games")
+	}
+}
@@ -1,27 +1,49 @@
 package state
 
+import (
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+)
+
 // Board holds the state of the game board
 type Board struct {
 	// PlayerState and OpponentState might be 2D arrays or a different structure
 	// depending on how you want to represent the board
-	PlayerState   [10][10]string
-	OpponentState [10][10]string
+	PlayerState   Grid
+	OpponentState Grid
 }
 
-// NewBoard returns a new Board
-func NewBoard() *Board {
+// NewBoard returns a new w×h Board
+func NewBoard(w, h int) *Board {
 	// Initialize the board to some default state
 	return &Board{
-		PlayerState:   [10][10]string{},
-		OpponentState: [10][10]string{},
+		PlayerState:   NewGrid(w, h),
+		OpponentState: NewGrid(w, h),
 	}
 }
 
 // UpdatePlayerStates updates the player states
-func (b *Board) updatePlayerStates(playerState [10][10]string) {
-	b.PlayerState = playerState
+func (b *Board) updatePlayerStates(playerState Grid) {
+	b.PlayerState = playerState.Clone()
 }
 
-func (b *Board) Mark(row, col int, state string) {
+func (b *Board) Mark(row, col int, state Cell) {
 	b.PlayerState[row][col] = state
 }
+
+// markSunk marks the fields around the ship sunk at c as missed and returns the ship length
+func (b *Board) markSunk(c coord.Coord) int {
+	hits := map[coord.Coord]bool{}
+	for x, row := range b.PlayerState {
+		for y, s := range row {
+			if s == Hit || s == Sunk {
+				hits[coord.FromIndex(x, y)] = true
+			}
+		}
+	}
+	ship := rules.SunkShip(hits, c)
+	for _, n := range ship.Border(b.PlayerState.Width(), b.PlayerState.Height()) {
+		b.Mark(n.X, n.Y, Miss)
+	}
+	return len(ship)
+}
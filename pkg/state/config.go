@@ -1,9 +1,12 @@
 package state
 
+// Cell is the state of a single board field
+type Cell string
+
 const (
-	Ship  = "Ship"
-	Empty = ""
-	Hit   = "Hit"
-	Miss  = "Miss"
-	Sunk  = "Sunk"
+	Ship  Cell = "Ship"
+	Empty Cell = ""
+	Hit   Cell = "Hit"
+	Miss  Cell = "Miss"
+	Sunk  Cell = "Sunk"
 )
@@ -0,0 +1,58 @@
+package state
+
+// Grid is a board of fields indexed [x][y], x being the column and y the row
+type Grid [][]Cell
+
+// NewGrid returns an empty w×h grid
+func NewGrid(w, h int) Grid {
+	g := make(Grid, w)
+	for x := range g {
+		g[x] = make([]Cell, h)
+	}
+	return g
+}
+
+// Width returns the number of columns
+func (g Grid) Width() int {
+	return len(g)
+}
+
+// Height returns the number of rows
+func (g Grid) Height() int {
+	if len(g) == 0 {
+		return 0
+	}
+	return len(g[0])
+}
+
+// In reports whether [x][y] lies on the grid
+func (g Grid) In(x, y int) bool {
+	return x >= 0 && x < g.Width() && y >= 0 && y < g.Height()
+}
+
+// Clone returns a deep copy of g
+func (g Grid) Clone() Grid {
+	c := make(Grid, len(g))
+	for x := range g {
+		c[x] = append([]Cell(nil), g[x]...)
+	}
+	return c
+}
+
+// Equal reports whether g and o have the same size and fields
+func (g Grid) Equal(o Grid) bool {
+	if len(g) != len(o) {
+		return false
+	}
+	for x := range g {
+		if len(g[x]) != len(o[x]) {
+			return false
+		}
+		for y := range g[x] {
+			if g[x][y] != o[x][y] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -2,6 +2,6 @@ package state
 
 // Player stores information about a player
 type Player struct {
-	Nick        string
-	Description string
+	Nick        string `json:"nick"`
+	Description string `json:"description"`
 }
@@ -1,7 +1,10 @@
 package state
 
 import (
+	"fmt"
 	"sync"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
 )
 
 // GameState manages the state of the game
@@ -15,25 +18,39 @@ type GameState struct {
 	m              sync.Mutex
 	lastGameStatus string
 	oppShipsSun    map[int]int
+	config         rules.Config
 }
 
-// NewGameState returns a new GameState
-func NewGameState() *GameState {
+// NewGameState returns a new GameState for the variant cfg
+func NewGameState(cfg rules.Config) *GameState {
 	return &GameState{
 		m:             sync.Mutex{},
 		player:        &Player{},
 		opponent:      &Player{},
-		playerBoard:   NewBoard(),
-		opponentBoard: NewBoard(),
-		oppShipsSun: map[int]int{
-			1: 4,
-			2: 3,
-			3: 2,
-			4: 1,
-		},
+		playerBoard:   NewBoard(cfg.Width, cfg.Height),
+		opponentBoard: NewBoard(cfg.Width, cfg.Height),
+		oppShipsSun:   cfg.Fleet.Clone(),
+		config:        cfg,
 	}
 }
 
+// Config returns the variant being played
+func (g *GameState) Config() rules.Config {
+	g.m.Lock()
+	defer g.m.Unlock()
+	cfg := g.config
+	cfg.Fleet = cfg.Fleet.Clone()
+	return cfg
+}
+
+// SetConfig switches to the variant cfg and clears the state
+func (g *GameState) SetConfig(cfg rules.Config) {
+	g.m.Lock()
+	g.config = cfg
+	g.m.Unlock()
+	g.ClearState()
+}
+
 // GetGameState returns the game state
 func (g *GameState) GetGameState() *GameState {
 	g.m.Lock()
@@ -52,26 +69,44 @@ func (g *GameState) UpdateGameState(nick, desc, opp, oppdesc string) {
 }
 
 // UpdatePlayerBoard updates the player board
-func (g *GameState) UpdatePlayerBoard(playerState [10][10]string) ([10][10]string, error) {
+func (g *GameState) UpdatePlayerBoard(playerState Grid) (Grid, error) {
 	g.m.Lock()
 	defer g.m.Unlock()
+	if err := g.checkGrid(playerState); err != nil {
+		return nil, err
+	}
 	g.playerBoard.updatePlayerStates(playerState)
-	return g.playerBoard.PlayerState, nil
+	return g.playerBoard.PlayerState.Clone(), nil
 }
-func (g *GameState) UpdateOpponentBoard(opponentState [10][10]string) ([10][10]string, error) {
+func (g *GameState) UpdateOpponentBoard(opponentState Grid) (Grid, error) {
 	g.m.Lock()
 	defer g.m.Unlock()
+	if err := g.checkGrid(opponentState); err != nil {
+		return nil, err
+	}
 	g.opponentBoard.updatePlayerStates(opponentState)
-	return g.opponentBoard.PlayerState, nil
+	return g.opponentBoard.PlayerState.Clone(), nil
 }
-func (g *GameState) GetPlayerBoard() [10][10]string {
+
+// checkGrid rejects grids whose size does not match the variant
+func (g *GameState) checkGrid(grid Grid) error {
+	if grid.Width() != g.config.Width || grid.Height() != g.config.Height {
+		return fmt.Errorf("board is %dx%d, expected %dx%d", grid.Width(), grid.Height(), g.config.Width, g.config.Height)
+	}
+	return nil
+}
+
+func (g *GameState) GetPlayerBoard() Grid {
 	g.m.Lock()
 	defer g.m.Unlock()
-	return g.playerBoard.PlayerState
+	return g.playerBoard.PlayerState.Clone()
 }
 func (g *GameState) MarkPlayerBoard(x, y int) {
 	g.m.Lock()
 	defer g.m.Unlock()
+	if !g.playerBoard.PlayerState.In(x, y) {
+		return
+	}
 	switch g.playerBoard.PlayerState[x][y] {
 	case Ship:
 		g.playerBoard.PlayerState[x][y] = Hit
@@ -80,18 +115,21 @@ func (g *GameState) MarkPlayerBoard(x, y int) {
 	}
 }
 
-func (g *GameState) GetOpponentBoard() [10][10]string {
+func (g *GameState) GetOpponentBoard() Grid {
 	g.m.Lock()
 	defer g.m.Unlock()
-	return g.opponentBoard.PlayerState
+	return g.opponentBoard.PlayerState.Clone()
 }
 
-func (g *GameState) MarkOpponentBoard(x int, y int, result string) int {
+func (g *GameState) MarkOpponentBoard(x int, y int, result Cell) int {
 	g.m.Lock()
 	defer g.m.Unlock()
+	if !g.opponentBoard.PlayerState.In(x, y) {
+		return 0
+	}
 	if result == Sunk {
 		g.opponentBoard.PlayerState[x][y] = result
-		_, l := g.opponentBoard.DrawBorder(x, y)
+		l := g.opponentBoard.markSunk(coord.FromIndex(x, y))
 		g.oppShipsSun[l]--
 		return l
 	}
@@ -103,14 +141,18 @@ func (g *GameState) MarkOpponentBoard(x int, y int, result string) int {
 func (g *GameState) IsHitAlready(x, y int) bool {
 	g.m.Lock()
 	defer g.m.Unlock()
+	if !g.opponentBoard.PlayerState.In(x, y) {
+		return false
+	}
 	s := g.opponentBoard.PlayerState[x][y]
 	return s == Hit || s == Miss
 }
 
-func (g *GameState) IncreaseHits(str string) {
+// IncreaseHits counts a shot fired by the player, hit tells whether it hit a ship
+func (g *GameState) IncreaseHits(hit bool) {
 	g.m.Lock()
 	defer g.m.Unlock()
-	if str == "hit" || str == "sunk" {
+	if hit {
 		g.hits++
 	}
 	g.totalShots++
@@ -161,22 +203,25 @@ func (g *GameState) UpdatePlayersDesc(desc, oppDesc string) {
 func (g *GameState) AddShip(x int, y int) {
 	g.m.Lock()
 	defer g.m.Unlock()
-	g.playerBoard.PlayerState[x][y] = Ship
+	if g.playerBoard.PlayerState.In(x, y) {
+		g.playerBoard.PlayerState[x][y] = Ship
+	}
 }
 
 func (g *GameState) ClearState() {
 	g.m.Lock()
 	defer g.m.Unlock()
-	g.playerBoard = NewBoard()
-	g.opponentBoard = NewBoard()
+	g.playerBoard = NewBoard(g.config.Width, g.config.Height)
+	g.opponentBoard = NewBoard(g.config.Width, g.config.Height)
 	g.totalShots = 0
 	g.hits = 0
+	g.oppShipsSun = g.config.Fleet.Clone()
 }
 
 func (g *GameState) GetOppShipsSunk() map[int]int {
 	g.m.Lock()
 	defer g.m.Unlock()
-	return g.oppShipsSun
+	return rules.Fleet(g.oppShipsSun).Clone()
 }
 
 func (g *GameState) UpdateLastGameStatus(status string) {
@@ -190,3 +235,67 @@ func (g *GameState) LastGameStatus() string {
 	defer g.m.Unlock()
 	return g.lastGameStatus
 }
+
+// Snapshot is a serialisable copy of the GameState
+type Snapshot struct {
+	Player         Player       `json:"player"`
+	Opponent       Player       `json:"opponent"`
+	Config         rules.Config `json:"config"`
+	PlayerBoard    Grid         `json:"player_board"`
+	OpponentBoard  Grid         `json:"opponent_board"`
+	TotalShots     int          `json:"total_shots"`
+	Hits           int          `json:"hits"`
+	OppShipsSunk   map[int]int  `json:"opp_ships_sunk"`
+	LastGameStatus string       `json:"last_game_status"`
+}
+
+// Snapshot returns a copy of the game state
+func (g *GameState) Snapshot() Snapshot {
+	g.m.Lock()
+	defer g.m.Unlock()
+	sunk := make(map[int]int, len(g.oppShipsSun))
+	for k, v := range g.oppShipsSun {
+		sunk[k] = v
+	}
+	cfg := g.config
+	cfg.Fleet = cfg.Fleet.Clone()
+	return Snapshot{
+		Player:         *g.player,
+		Opponent:       *g.opponent,
+		Config:         cfg,
+		PlayerBoard:    g.playerBoard.PlayerState.Clone(),
+		OpponentBoard:  g.opponentBoard.PlayerState.Clone(),
+		TotalShots:     g.totalShots,
+		Hits:           g.hits,
+		OppShipsSunk:   sunk,
+		LastGameStatus: g.lastGameStatus,
+	}
+}
+
+// Restore replaces the game state with the snapshot
+func (g *GameState) Restore(s Snapshot) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	g.player = &Player{Nick: s.Player.Nick, Description: s.Player.Description}
+	g.opponent = &Player{Nick: s.Opponent.Nick, Description: s.Opponent.Description}
+	// snapshots saved before variants existed are classic games
+	if s.Config.Width == 0 {
+		s.Config = rules.Classic()
+	}
+	g.config = s.Config
+	g.playerBoard = NewBoard(s.Config.Width, s.Config.Height)
+	g.opponentBoard = NewBoard(s.Config.Width, s.Config.Height)
+	if g.checkGrid(s.PlayerBoard) == nil {
+		g.playerBoard.updatePlayerStates(s.PlayerBoard)
+	}
+	if g.checkGrid(s.OpponentBoard) == nil {
+		g.opponentBoard.updatePlayerStates(s.OpponentBoard)
+	}
+	g.totalShots = s.TotalShots
+	g.hits = s.Hits
+	g.oppShipsSun = g.config.Fleet.Clone()
+	for k, v := range s.OppShipsSunk {
+		g.oppShipsSun[k] = v
+	}
+	g.lastGameStatus = s.LastGameStatus
+}
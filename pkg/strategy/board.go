@@ -0,0 +1,83 @@
+package strategy
+
+import (
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+)
+
+// Shot is a shot fired at the opponent together with its result
+type Shot struct {
+	Coord  coord.Coord
+	Result rules.Result
+}
+
+// Board rebuilds what is known of the opponent board from the shots fired at
+// it, the way the client keeps it: fields around sunk ships are marked as
+// missed. It also returns the ships still afloat.
+func Board(cfg rules.Config, shots []Shot) (state.Grid, rules.Fleet) {
+	board := state.NewGrid(cfg.Width, cfg.Height)
+	remaining := cfg.Fleet.Clone()
+	hits := map[coord.Coord]bool{}
+	for _, s := range shots {
+		if !cfg.Contains(s.Coord) {
+			continue
+		}
+		switch s.Result {
+		case rules.Miss:
+			if board[s.Coord.X][s.Coord.Y] == state.Empty {
+				board[s.Coord.X][s.Coord.Y] = state.Miss
+			}
+		case rules.Hit:
+			board[s.Coord.X][s.Coord.Y] = state.Hit
+			hits[s.Coord] = true
+		case rules.Sunk:
+			board[s.Coord.X][s.Coord.Y] = state.Sunk
+			hits[s.Coord] = true
+			ship := rules.SunkShip(hits, s.Coord)
+			for _, n := range ship.Border(cfg.Width, cfg.Height) {
+				board[n.X][n.Y] = state.Miss
+			}
+			remaining[len(ship)]--
+		}
+	}
+	return board, remaining
+}
+
+// unknown reports whether nothing is known about a field
+func unknown(s state.Cell) bool {
+	return s == state.Empty || s == state.Ship
+}
+
+// unshot returns the fields not fired at yet
+func unshot(cfg rules.Config, board state.Grid) []coord.Coord {
+	var cs []coord.Coord
+	for _, c := range cfg.Fields() {
+		if board.In(c.X, c.Y) && unknown(board[c.X][c.Y]) {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// wounded returns the hit ships not sunk yet, longest first
+func wounded(cfg rules.Config, board state.Grid) []rules.Ship {
+	hits := map[coord.Coord]bool{}
+	for _, c := range cfg.Fields() {
+		if s := board[c.X][c.Y]; s == state.Hit || s == state.Sunk {
+			hits[c] = true
+		}
+	}
+	open := map[coord.Coord]bool{}
+	for c := range hits {
+		open[c] = true
+	}
+	for c := range hits {
+		if board[c.X][c.Y] == state.Sunk {
+			for _, d := range rules.SunkShip(hits, c) {
+				delete(open, d)
+			}
+		}
+	}
+	return rules.Ships(open)
+}
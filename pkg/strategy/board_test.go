@@ -0,0 +1,40 @@
+package strategy
+
+import (
+	"reflect"
+	"testing"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+)
+
+func TestBoard(t *testing.T) {
+	cfg := rules.Classic()
+	shots := []Shot{
+		{coord.MustParse("C5"), rules.Miss},
+		{coord.MustParse("A1"), rules.Hit},
+		{coord.MustParse("A2"), rules.Sunk},
+		{coord.MustParse("E5"), rules.Hit},
+		{coord.MustParse("J10"), rules.Sunk},
+		{coord.FromIndex(10, 0), rules.Hit},
+	}
+	board, remaining := Board(cfg, shots)
+
+	want := state.NewGrid(cfg.Width, cfg.Height)
+	for cell, fields := range map[state.Cell][]string{
+		state.Miss: {"C5", "A3", "B1", "B2", "B3", "I9", "I10", "J9"},
+		state.Hit:  {"A1", "E5"},
+		state.Sunk: {"A2", "J10"},
+	} {
+		for _, s := range fields {
+			c := coord.MustParse(s)
+			want[c.X][c.Y] = cell
+		}
+	}
+	if !board.Equal(want) {
+		t.Errorf("got board %v, want %v", board, want)
+	}
+	if want := (rules.Fleet{4: 1, 3: 2, 2: 2, 1: 3}); !reflect.DeepEqual(remaining, want) {
+		t.Errorf("got remaining %v, want %v", remaining, want)
+	}
+}
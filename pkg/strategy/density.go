@@ -0,0 +1,32 @@
+package strategy
+
+import (
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+	"warships/pkg/targeting"
+)
+
+type density struct {
+	noise noise
+}
+
+// NewDensity fires at the field most likely to hold a ship, counting every
+// placement of the ships afloat that fits what is known of the board
+func NewDensity(opts Options) Strategy {
+	return &density{noise: newNoise(opts)}
+}
+
+func (s *density) Next(cfg rules.Config, board state.Grid, remaining rules.Fleet) (coord.Coord, error) {
+	if s.noise.random() {
+		return s.noise.pick(unshot(cfg, board))
+	}
+	if c, ok := targeting.Compute(cfg, board, remaining).Best(); ok {
+		return c, nil
+	}
+	return s.noise.pick(unshot(cfg, board))
+}
+
+func (s *density) Rank(cfg rules.Config, board state.Grid, remaining rules.Fleet) []targeting.Rated {
+	return targeting.Compute(cfg, board, remaining).Ranked()
+}
@@ -0,0 +1,103 @@
+package strategy
+
+import (
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+)
+
+type random struct {
+	noise noise
+}
+
+// NewRandom fires at random fields not shot yet, noise has no effect
+func NewRandom(opts Options) Strategy {
+	return &random{noise: newNoise(opts)}
+}
+
+func (s *random) Next(cfg rules.Config, board state.Grid, remaining rules.Fleet) (coord.Coord, error) {
+	return s.noise.pick(unshot(cfg, board))
+}
+
+type hunt struct {
+	noise  noise
+	parity bool
+}
+
+// NewHunt fires at random until a ship is hit and then around the hit,
+// along the ship once its direction is known, until it is sunk
+func NewHunt(opts Options) Strategy {
+	return &hunt{noise: newNoise(opts)}
+}
+
+// NewParity hunts like NewHunt but only on fields spaced by the shortest
+// ship afloat, on a checkerboard while no single field ship is left
+func NewParity(opts Options) Strategy {
+	return &hunt{noise: newNoise(opts), parity: true}
+}
+
+func (s *hunt) Next(cfg rules.Config, board state.Grid, remaining rules.Fleet) (coord.Coord, error) {
+	free := unshot(cfg, board)
+	if s.noise.random() {
+		return s.noise.pick(free)
+	}
+	for _, ship := range wounded(cfg, board) {
+		if cs := s.around(cfg, board, ship); len(cs) > 0 {
+			return s.noise.pick(cs)
+		}
+	}
+	if cs := s.candidates(cfg, board, free, remaining); len(cs) > 0 {
+		return s.noise.pick(cs)
+	}
+	return s.noise.pick(free)
+}
+
+// around returns the fields that may continue a hit ship
+func (s *hunt) around(cfg rules.Config, board state.Grid, ship rules.Ship) []coord.Coord {
+	var ends []coord.Coord
+	switch {
+	case len(ship) == 1:
+		ends = ship[0].Neighbours(cfg.Width, cfg.Height)
+	case ship[0].X == ship[1].X:
+		ends = []coord.Coord{ship[0].Add(0, -1), ship[len(ship)-1].Add(0, 1)}
+	default:
+		ends = []coord.Coord{ship[0].Add(-1, 0), ship[len(ship)-1].Add(1, 0)}
+	}
+	var cs []coord.Coord
+	for _, c := range ends {
+		if cfg.Contains(c) && unknown(board[c.X][c.Y]) {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// candidates returns the free fields that may hold a ship not found yet:
+// fields next to a hit cannot, ships never touch
+func (s *hunt) candidates(cfg rules.Config, board state.Grid, free []coord.Coord, remaining rules.Fleet) []coord.Coord {
+	step := 1
+	if s.parity {
+		for _, n := range remaining.Sizes() {
+			if remaining[n] > 0 {
+				step = n
+			}
+		}
+	}
+	var cs []coord.Coord
+	for _, c := range free {
+		if (c.X+c.Y)%step != 0 || nextToHit(cfg, board, c) {
+			continue
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+func nextToHit(cfg rules.Config, board state.Grid, c coord.Coord) bool {
+	for _, n := range c.Around(cfg.Width, cfg.Height) {
+		if s := board[n.X][n.Y]; s == state.Hit || s == state.Sunk {
+			return true
+		}
+	}
+	return false
+}
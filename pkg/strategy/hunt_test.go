@@ -0,0 +1,109 @@
+package strategy
+
+import (
+	"testing"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+)
+
+func TestHuntFinishesShip(t *testing.T) {
+	tests := []struct {
+		name   string
+		hits   []string
+		misses []string
+		want   []string
+	}{
+		{"single hit", []string{"E5"}, nil, []string{"E4", "E6", "D5", "F5"}},
+		{"single hit in the corner", []string{"A1"}, []string{"A2"}, []string{"B1"}},
+		{"two hits", []string{"E5", "E6"}, nil, []string{"E4", "E7"}},
+		{"two hits with one end missed", []string{"E5", "E6"}, []string{"E4"}, []string{"E7"}},
+		{"three hits across", []string{"C2", "D2", "E2"}, nil, []string{"B2", "F2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := rules.Classic()
+			board := state.NewGrid(cfg.Width, cfg.Height)
+			mark(board, state.Hit, tt.hits...)
+			mark(board, state.Miss, tt.misses...)
+			for _, name := range []string{"hunt", "parity"} {
+				for seed := int64(1); seed <= 20; seed++ {
+					s, err := New(name, Options{Seed: seed})
+					if err != nil {
+						t.Fatal(err)
+					}
+					c, err := s.Next(cfg, board, cfg.Fleet)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !in(tt.want, c) {
+						t.Fatalf("%s fired at %v, want one of %v", name, c, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParityLattice(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining rules.Fleet
+		step      int
+	}{
+		{"no single-masters", rules.Fleet{4: 1, 3: 2, 2: 3, 1: 0}, 2},
+		{"only long ships", rules.Fleet{4: 1, 3: 1, 2: 0}, 3},
+		{"only the four-master", rules.Fleet{4: 1}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := rules.Classic()
+			board := state.NewGrid(cfg.Width, cfg.Height)
+			s := NewParity(Options{Seed: 1})
+			// fewer shots than the lattice has fields
+			for i := 0; i < 20; i++ {
+				c, err := s.Next(cfg, board, tt.remaining)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (c.X+c.Y)%tt.step != 0 {
+					t.Fatalf("fired at %v off the lattice of step %d", c, tt.step)
+				}
+				board[c.X][c.Y] = state.Miss
+			}
+		})
+	}
+}
+
+func TestParityWithSingleMasters(t *testing.T) {
+	cfg := rules.Classic()
+	board := state.NewGrid(cfg.Width, cfg.Height)
+	s := NewParity(Options{Seed: 1})
+	for i := 0; i < 30; i++ {
+		c, err := s.Next(cfg, board, cfg.Fleet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (c.X+c.Y)%2 != 0 {
+			return
+		}
+		board[c.X][c.Y] = state.Miss
+	}
+	t.Error("never fired off the checkerboard with single-masters afloat")
+}
+
+func mark(board state.Grid, cell state.Cell, fields ...string) {
+	for _, s := range fields {
+		c := coord.MustParse(s)
+		board[c.X][c.Y] = cell
+	}
+}
+
+func in(fields []string, c coord.Coord) bool {
+	for _, s := range fields {
+		if coord.MustParse(s) == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+// Package strategy holds the ways of choosing the next shot, selected by
+// name from a registry shared by the autopilot, the hints and the bots.
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+	"warships/pkg/targeting"
+)
+
+// ErrNoTarget is returned when every field was shot already
+var ErrNoTarget = errors.New("no field left to shoot at")
+
+// Strategy chooses the next shot at the opponent board. board holds the
+// hits, misses and sunk ships known so far and remaining the ships afloat.
+// A strategy keeps its own random source and is not safe for concurrent use.
+type Strategy interface {
+	Next(cfg rules.Config, board state.Grid, remaining rules.Fleet) (coord.Coord, error)
+}
+
+// Ranker is implemented by strategies able to rate every field, the most
+// promising first
+type Ranker interface {
+	Rank(cfg rules.Config, board state.Grid, remaining rules.Fleet) []targeting.Rated
+}
+
+// Options configure a strategy
+type Options struct {
+	// Noise is the chance, from 0 to 1, of firing at a random field instead,
+	// 0 plays as well as the strategy can
+	Noise float64
+	// Seed seeds the random source, 0 picks a new one
+	Seed int64
+}
+
+func (o Options) rand() *rand.Rand {
+	seed := o.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// Factory builds a strategy
+type Factory func(opts Options) Strategy
+
+var (
+	mu       sync.Mutex
+	registry = map[string]Factory{}
+)
+
+func init() {
+	Register("random", NewRandom)
+	Register("parity", NewParity)
+	Register("hunt", NewHunt)
+	Register("density", NewDensity)
+}
+
+// Register makes a strategy available under name, replacing any previous one
+func Register(name string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[name] = f
+}
+
+// New builds the strategy registered under name
+func New(name string, opts Options) (Strategy, error) {
+	mu.Lock()
+	f, ok := registry[name]
+	mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	if opts.Noise < 0 || opts.Noise > 1 {
+		return nil, fmt.Errorf("noise %v is not between 0 and 1", opts.Noise)
+	}
+	return f(opts), nil
+}
+
+// Names returns the registered strategies sorted by name
+func Names() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromState asks s for the next shot at the opponent board of gs
+func FromState(s Strategy, gs *state.GameState) (coord.Coord, error) {
+	return s.Next(gs.Config(), gs.GetOpponentBoard(), rules.Fleet(gs.GetOppShipsSunk()))
+}
+
+// noise fires at random fields instead of following the strategy
+type noise struct {
+	chance float64
+	rnd    *rand.Rand
+}
+
+func newNoise(opts Options) noise {
+	return noise{chance: opts.Noise, rnd: opts.rand()}
+}
+
+// random reports whether this shot should go to a random field
+func (n noise) random() bool {
+	return n.chance > 0 && n.rnd.Float64() < n.chance
+}
+
+// pick returns one of cs at random
+func (n noise) pick(cs []coord.Coord) (coord.Coord, error) {
+	if len(cs) == 0 {
+		return coord.Coord{}, ErrNoTarget
+	}
+	return cs[n.rnd.Intn(len(cs))], nil
+}
@@ -0,0 +1,61 @@
+package strategy
+
+import (
+	"fmt"
+	"testing"
+	"warships/pkg/layout"
+	"warships/pkg/rules"
+)
+
+func TestNeverRepeats(t *testing.T) {
+	for _, name := range Names() {
+		for _, noise := range []float64{0, 0.5} {
+			t.Run(fmt.Sprintf("%s noise %v", name, noise), func(t *testing.T) {
+				for _, cfg := range []rules.Config{rules.Classic(), rules.Training()} {
+					for seed := int64(1); seed <= 5; seed++ {
+						play(t, name, cfg, Options{Noise: noise, Seed: seed})
+					}
+				}
+			})
+		}
+	}
+}
+
+// play lets the strategy sink a generated fleet, failing on a repeated shot
+func play(t *testing.T, name string, cfg rules.Config, opts Options) {
+	t.Helper()
+	s, err := New(name, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := layout.NewGenerator(cfg, opts.Seed).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := rules.NewBoard(cfg, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shots []Shot
+	for !target.Over() {
+		board, remaining := Board(cfg, shots)
+		c, err := s.Next(cfg, board, remaining)
+		if err != nil {
+			t.Fatalf("after %d shots: %v", len(shots), err)
+		}
+		r, err := target.Fire(c)
+		if err != nil {
+			t.Fatalf("shot %d at %v: %v", len(shots)+1, c, err)
+		}
+		shots = append(shots, Shot{Coord: c, Result: r})
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("sniper", Options{}); err == nil {
+		t.Error("built an unknown strategy")
+	}
+	if _, err := New("hunt", Options{Noise: 1.5}); err == nil {
+		t.Error("accepted a noise above 1")
+	}
+}
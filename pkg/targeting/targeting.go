@@ -0,0 +1,271 @@
+// Package targeting rates the fields of the opponent board by the chance
+// that a ship occupies them.
+package targeting
+
+import (
+	"sort"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+)
+
+// Rated is a field together with its score, see Heatmap.P
+type Rated struct {
+	Coord coord.Coord
+	P     float64
+}
+
+// Heatmap holds the chance of a ship for every field of the opponent board.
+// Fields already shot have no chance.
+type Heatmap struct {
+	cfg rules.Config
+	p   []float64
+	// Target is set when a ship was hit but not sunk, the chances then
+	// belong to the fields of that ship
+	Target bool
+}
+
+// FromState builds the heatmap of the opponent board in gs
+func FromState(gs *state.GameState) Heatmap {
+	return Compute(gs.Config(), gs.GetOpponentBoard(), rules.Fleet(gs.GetOppShipsSunk()))
+}
+
+// Compute builds the heatmap of board, a grid of hits, misses and sunk
+// ships, for the ships in remaining that are still afloat.
+//
+// Every legal placement of every remaining ship is counted: it must not
+// cover a missed or sunk field, touch a sunk ship or touch a hit it does not
+// cover. While there are hits of a ship not yet sunk only placements covering
+// them are counted, so that the ship is finished first.
+//
+// The placements of each ship are counted on their own, as if the other
+// ships were not on the board, and the shares of the ships are added up.
+// The result is a score for ranking fields rather than a true probability,
+// which would need the placements of the whole fleet enumerated together.
+func Compute(cfg rules.Config, board state.Grid, remaining rules.Fleet) Heatmap {
+	w, h := cfg.Width, cfg.Height
+	m := Heatmap{cfg: cfg, p: make([]float64, w*h)}
+	at := func(c coord.Coord) state.Cell {
+		if !board.In(c.X, c.Y) {
+			return state.Miss
+		}
+		return board[c.X][c.Y]
+	}
+
+	// fields of sunk ships, grown from every field reported as sunk
+	hits := map[coord.Coord]bool{}
+	for _, c := range cfg.Fields() {
+		if s := at(c); s == state.Hit || s == state.Sunk {
+			hits[c] = true
+		}
+	}
+	dead := map[coord.Coord]bool{}
+	for c := range hits {
+		if at(c) == state.Sunk && !dead[c] {
+			for _, d := range rules.SunkShip(hits, c) {
+				dead[d] = true
+			}
+		}
+	}
+
+	blocked := make([]bool, w*h)
+	open := make([]bool, w*h)
+	for _, c := range cfg.Fields() {
+		switch {
+		case dead[c]:
+			blocked[m.index(c)] = true
+			for _, n := range c.Around(w, h) {
+				blocked[m.index(n)] = true
+			}
+		case hits[c]:
+			open[m.index(c)] = true
+		case at(c) == state.Miss:
+			blocked[m.index(c)] = true
+		}
+	}
+	for _, o := range open {
+		if o {
+			m.Target = true
+			break
+		}
+	}
+
+	// count adds up the placements of every remaining ship. Hunting, each ship
+	// adds the share of its placements covering a field. Targeting, the hit
+	// ship is one of the placements covering the hits, all equally likely.
+	count := func(target bool) bool {
+		total := 0.0
+		cover := make([]float64, w*h)
+		for _, n := range remaining.Sizes() {
+			if remaining[n] <= 0 {
+				continue
+			}
+			var legal []rules.Ship
+			for _, ship := range placements(cfg, n) {
+				if !m.legal(ship, blocked, open) || target && !m.covers(ship, open) {
+					continue
+				}
+				legal = append(legal, ship)
+			}
+			if len(legal) == 0 {
+				continue
+			}
+			weight := float64(remaining[n])
+			if !target {
+				weight /= float64(len(legal))
+			}
+			for _, ship := range legal {
+				total += weight
+				for _, c := range ship {
+					cover[m.index(c)] += weight
+				}
+			}
+		}
+		if total == 0 {
+			return false
+		}
+		if target {
+			for i := range cover {
+				cover[i] /= total
+			}
+		}
+		for i := range cover {
+			if !open[i] && !blocked[i] {
+				m.p[i] = cover[i]
+			}
+		}
+		return true
+	}
+	if !count(m.Target) && m.Target {
+		// the hits cannot be explained by the remaining fleet, hunt instead
+		m.Target = false
+		count(false)
+	}
+
+	// shot fields have no chance left
+	for _, c := range cfg.Fields() {
+		if at(c) != state.Empty && at(c) != state.Ship {
+			m.p[m.index(c)] = 0
+		}
+	}
+	if !m.Target {
+		// the shares of the ships add up, cap the score of a field at 1
+		for i := range m.p {
+			if m.p[i] > 1 {
+				m.p[i] = 1
+			}
+		}
+	}
+	return m
+}
+
+// legal reports whether ship avoids blocked fields and touches no hit it does not cover
+func (m Heatmap) legal(ship rules.Ship, blocked, open []bool) bool {
+	for _, c := range ship {
+		if blocked[m.index(c)] {
+			return false
+		}
+	}
+	for _, c := range ship {
+		for _, n := range c.Around(m.cfg.Width, m.cfg.Height) {
+			if open[m.index(n)] && !ship.Contains(n) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// covers reports whether ship covers a hit
+func (m Heatmap) covers(ship rules.Ship, open []bool) bool {
+	for _, c := range ship {
+		if open[m.index(c)] {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Heatmap) index(c coord.Coord) int {
+	return c.X*m.cfg.Height + c.Y
+}
+
+// Config returns the variant the heatmap was computed for
+func (m Heatmap) Config() rules.Config {
+	return m.cfg
+}
+
+// P returns the score of c between 0 and 1, an estimate of the chance that a
+// ship occupies it. It is not a probability, see Compute.
+func (m Heatmap) P(c coord.Coord) float64 {
+	if !m.cfg.Contains(c) {
+		return 0
+	}
+	return m.p[m.index(c)]
+}
+
+// Max returns the highest chance on the board
+func (m Heatmap) Max() float64 {
+	top := 0.0
+	for _, p := range m.p {
+		if p > top {
+			top = p
+		}
+	}
+	return top
+}
+
+// Ranked returns the fields with a chance above zero, the most likely first.
+// Equal chances keep the column by column order of the board.
+func (m Heatmap) Ranked() []Rated {
+	var ranked []Rated
+	for _, c := range m.cfg.Fields() {
+		if p := m.P(c); p > 0 {
+			ranked = append(ranked, Rated{Coord: c, P: p})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].P > ranked[j].P
+	})
+	return ranked
+}
+
+// Top returns at most n of the most likely fields
+func (m Heatmap) Top(n int) []Rated {
+	ranked := m.Ranked()
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// Best returns the most likely field, false when no field is left to shoot
+func (m Heatmap) Best() (coord.Coord, bool) {
+	top := m.Top(1)
+	if len(top) == 0 {
+		return coord.Coord{}, false
+	}
+	return top[0].Coord, true
+}
+
+// placements returns every straight ship of length n on the board of cfg
+func placements(cfg rules.Config, n int) []rules.Ship {
+	var ships []rules.Ship
+	for _, c := range cfg.Fields() {
+		if c.X+n <= cfg.Width {
+			ship := make(rules.Ship, n)
+			for i := range ship {
+				ship[i] = c.Add(i, 0)
+			}
+			ships = append(ships, ship)
+		}
+		if n > 1 && c.Y+n <= cfg.Height {
+			ship := make(rules.Ship, n)
+			for i := range ship {
+				ship[i] = c.Add(0, i)
+			}
+			ships = append(ships, ship)
+		}
+	}
+	return ships
+}
@@ -0,0 +1,146 @@
+package targeting
+
+import (
+	"math"
+	"testing"
+	"warships/pkg/coord"
+	"warships/pkg/rules"
+	"warships/pkg/state"
+)
+
+func TestHunt(t *testing.T) {
+	cfg := rules.Classic()
+	m := Compute(cfg, state.NewGrid(cfg.Width, cfg.Height), cfg.Fleet)
+	if m.Target {
+		t.Error("targeting an empty board")
+	}
+	w, h := cfg.Width, cfg.Height
+	for _, c := range cfg.Fields() {
+		p := m.P(c)
+		for _, mirror := range []coord.Coord{
+			coord.FromIndex(w-1-c.X, c.Y),
+			coord.FromIndex(c.X, h-1-c.Y),
+			coord.FromIndex(c.Y, c.X),
+		} {
+			if math.Abs(m.P(mirror)-p) > 1e-9 {
+				t.Errorf("%v has %v, its mirror %v has %v", c, p, mirror, m.P(mirror))
+			}
+		}
+	}
+	// the chances grow from the corner up to the centre, D4 to G7
+	prev := 0.0
+	for _, s := range []string{"A1", "B2", "C3", "D4"} {
+		p := m.P(coord.MustParse(s))
+		if p <= prev {
+			t.Errorf("%s has %v, no more than %v closer to the corner", s, p, prev)
+		}
+		prev = p
+	}
+	if top := m.Max(); math.Abs(prev-top) > 1e-9 {
+		t.Errorf("the centre has %v, the top is %v", prev, top)
+	}
+}
+
+func TestTarget(t *testing.T) {
+	tests := []struct {
+		name string
+		hits []string
+		// best are the fields one of which should be fired at next
+		best []string
+		// zero are fields which cannot hold the hit ship
+		zero []string
+	}{
+		{"single hit", []string{"E5"}, []string{"E4", "E6", "D5", "F5"}, []string{"E5", "D4", "F4", "D6", "F6"}},
+		{"two aligned hits", []string{"E5", "E6"}, []string{"E4", "E7"}, []string{"D5", "F5", "D6", "F6"}},
+		{"hits at the edge", []string{"A1", "B1"}, []string{"C1"}, []string{"A2", "B2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := rules.Classic()
+			m := Compute(cfg, grid(cfg, state.Hit, tt.hits...), cfg.Fleet)
+			if !m.Target {
+				t.Fatal("not targeting")
+			}
+			best, ok := m.Best()
+			if !ok || !contains(tt.best, best) {
+				t.Errorf("got best %v, want one of %v", best, tt.best)
+			}
+			for _, s := range tt.zero {
+				if p := m.P(coord.MustParse(s)); p != 0 {
+					t.Errorf("%s has %v", s, p)
+				}
+			}
+		})
+	}
+}
+
+func TestSunkShipBlocked(t *testing.T) {
+	cfg := rules.Classic()
+	board := grid(cfg, state.Hit, "A1")
+	board[0][1] = state.Sunk
+	remaining := cfg.Fleet.Clone()
+	remaining[2]--
+	m := Compute(cfg, board, remaining)
+	if m.Target {
+		t.Error("targeting a sunk ship")
+	}
+	for _, s := range []string{"A1", "A2", "A3", "B1", "B2", "B3"} {
+		if p := m.P(coord.MustParse(s)); p != 0 {
+			t.Errorf("%s has %v", s, p)
+		}
+	}
+	if m.P(coord.MustParse("A4")) == 0 {
+		t.Error("A4 is blocked")
+	}
+}
+
+func TestUnexplainedHits(t *testing.T) {
+	cfg := rules.Classic()
+	// two aligned hits cannot belong to a single-master
+	m := Compute(cfg, grid(cfg, state.Hit, "E5", "E6"), rules.Fleet{1: 1})
+	if m.Target {
+		t.Error("targeting hits no ship can cover")
+	}
+	for _, s := range []string{"E4", "E7", "D5", "F6"} {
+		if p := m.P(coord.MustParse(s)); p != 0 {
+			t.Errorf("%s touches the hits and has %v", s, p)
+		}
+	}
+	if _, ok := m.Best(); !ok {
+		t.Error("no field left to hunt")
+	}
+}
+
+func TestBestFullyShot(t *testing.T) {
+	cfg := rules.Training()
+	board := state.NewGrid(cfg.Width, cfg.Height)
+	for _, c := range cfg.Fields() {
+		board[c.X][c.Y] = state.Miss
+	}
+	m := Compute(cfg, board, cfg.Fleet)
+	if c, ok := m.Best(); ok {
+		t.Errorf("got best %v on a fully shot board", c)
+	}
+	if len(m.Ranked()) != 0 || m.Max() != 0 {
+		t.Errorf("got ranked %v", m.Ranked())
+	}
+}
+
+// grid returns an empty board of cfg with cell at the given fields
+func grid(cfg rules.Config, cell state.Cell, fields ...string) state.Grid {
+	g := state.NewGrid(cfg.Width, cfg.Height)
+	for _, s := range fields {
+		c := coord.MustParse(s)
+		g[c.X][c.Y] = cell
+	}
+	return g
+}
+
+func contains(fields []string, c coord.Coord) bool {
+	for _, s := range fields {
+		if coord.MustParse(s) == c {
+			return true
+		}
+	}
+	return false
+}